@@ -0,0 +1,173 @@
+package ambient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrNoAmbientCredentials is returned when no ambient credentials are
+// available, so that callers can fall back to explicit credentials.
+var ErrNoAmbientCredentials = errors.New("no ambient credentials")
+
+// Endpoints of the metadata servers
+const (
+	gcpTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	awsRolesURL = "http://169.254.169.254/latest/meta-data/iam/security-credentials/"
+)
+
+const (
+	defaultTimeout  = 2 * time.Second
+	maxResponseSize = 1 << 20
+)
+
+// AmbientCredentialProvider obtains a token from the credentials provided by
+// the environment, e.g. CI systems, without explicit configuration.
+// The sources are probed in order: the GitHub Actions OIDC token, the GCP
+// metadata server, and the AWS instance metadata service.
+type AmbientCredentialProvider struct {
+	// Client is the HTTP client for the probes. http.DefaultClient is used if
+	// nil.
+	Client *http.Client
+
+	// Audience is the audience requested for the GitHub Actions OIDC token.
+	Audience string
+
+	// Timeout bounds each probe, since the metadata servers are unreachable
+	// outside of their cloud. It defaults to 2 seconds.
+	Timeout time.Duration
+}
+
+// Token returns the token of the first available source, or
+// ErrNoAmbientCredentials if none is available.
+func (p *AmbientCredentialProvider) Token(ctx context.Context) (string, error) {
+	probes := []func(context.Context) (string, error){
+		p.github,
+		p.gcp,
+		p.aws,
+	}
+	for _, probe := range probes {
+		if token, err := p.probe(ctx, probe); err == nil && token != "" {
+			return token, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+	}
+	return "", ErrNoAmbientCredentials
+}
+
+func (p *AmbientCredentialProvider) probe(ctx context.Context, probe func(context.Context) (string, error)) (string, error) {
+	timeout := p.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return probe(ctx)
+}
+
+// github requests the OIDC token of GitHub Actions
+func (p *AmbientCredentialProvider) github(ctx context.Context) (string, error) {
+	requestURL := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL")
+	requestToken := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+	if requestURL == "" || requestToken == "" {
+		return "", ErrNoAmbientCredentials
+	}
+	if p.Audience != "" {
+		u, err := url.Parse(requestURL)
+		if err != nil {
+			return "", err
+		}
+		q := u.Query()
+		q.Set("audience", p.Audience)
+		u.RawQuery = q.Encode()
+		requestURL = u.String()
+	}
+
+	var result struct {
+		Value string `json:"value"`
+	}
+	header := http.Header{
+		"Authorization": {"Bearer " + requestToken},
+	}
+	if err := p.getJSON(ctx, requestURL, header, &result); err != nil {
+		return "", err
+	}
+	return result.Value, nil
+}
+
+// gcp requests the access token of the default service account from the GCP
+// metadata server
+func (p *AmbientCredentialProvider) gcp(ctx context.Context) (string, error) {
+	var result struct {
+		AccessToken string `json:"access_token"`
+	}
+	header := http.Header{
+		"Metadata-Flavor": {"Google"},
+	}
+	if err := p.getJSON(ctx, gcpTokenURL, header, &result); err != nil {
+		return "", err
+	}
+	return result.AccessToken, nil
+}
+
+// aws requests the session token of the instance role from the AWS instance
+// metadata service
+func (p *AmbientCredentialProvider) aws(ctx context.Context) (string, error) {
+	roles, err := p.get(ctx, awsRolesURL, nil)
+	if err != nil {
+		return "", err
+	}
+	role := strings.TrimSpace(strings.SplitN(string(roles), "\n", 2)[0])
+	if role == "" {
+		return "", ErrNoAmbientCredentials
+	}
+
+	var result struct {
+		Token string `json:"Token"`
+	}
+	if err := p.getJSON(ctx, awsRolesURL+role, nil, &result); err != nil {
+		return "", err
+	}
+	return result.Token, nil
+}
+
+func (p *AmbientCredentialProvider) getJSON(ctx context.Context, url string, header http.Header, v interface{}) error {
+	body, err := p.get(ctx, url, header)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+func (p *AmbientCredentialProvider) get(ctx context.Context, url string, header http.Header) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get %s: %s", url, resp.Status)
+	}
+	return ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+}
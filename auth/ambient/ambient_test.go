@@ -0,0 +1,128 @@
+package ambient_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/notaryproject/notary/v2/auth/ambient"
+)
+
+// setenv sets the environment variable for the duration of the test
+func setenv(t *testing.T, key, value string) {
+	t.Helper()
+	old, ok := os.LookupEnv(key)
+	if err := os.Setenv(key, value); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// fakeMetadataClient answers the requests with the responses keyed by URL
+// without query, and with 404 for the others. Requests failing check are
+// answered with 403.
+func fakeMetadataClient(responses map[string]string, check func(*http.Request) bool) *http.Client {
+	return &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			status := http.StatusOK
+			body, ok := responses[strings.SplitN(req.URL.String(), "?", 2)[0]]
+			switch {
+			case !ok:
+				status = http.StatusNotFound
+			case check != nil && !check(req):
+				status = http.StatusForbidden
+			}
+			return &http.Response{
+				StatusCode: status,
+				Status:     http.StatusText(status),
+				Body:       ioutil.NopCloser(strings.NewReader(body)),
+				Request:    req,
+			}, nil
+		}),
+	}
+}
+
+func TestAmbientCredentialProviderToken(t *testing.T) {
+	const githubURL = "https://github.example.com/token"
+	for _, tt := range []struct {
+		name      string
+		github    bool
+		responses map[string]string
+		check     func(*http.Request) bool
+		want      string
+	}{
+		{
+			name:      "github",
+			github:    true,
+			responses: map[string]string{githubURL: `{"value":"github-token"}`},
+			check: func(req *http.Request) bool {
+				return req.Header.Get("Authorization") == "Bearer request-token" && req.URL.Query().Get("audience") == "registry.example.com"
+			},
+			want: "github-token",
+		},
+		{
+			name: "gcp",
+			responses: map[string]string{
+				"http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token": `{"access_token":"gcp-token"}`,
+			},
+			check: func(req *http.Request) bool {
+				return req.Header.Get("Metadata-Flavor") == "Google"
+			},
+			want: "gcp-token",
+		},
+		{
+			name: "aws",
+			responses: map[string]string{
+				"http://169.254.169.254/latest/meta-data/iam/security-credentials/":     "role\nother",
+				"http://169.254.169.254/latest/meta-data/iam/security-credentials/role": `{"Token":"aws-token"}`,
+			},
+			want: "aws-token",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.github {
+				setenv(t, "ACTIONS_ID_TOKEN_REQUEST_URL", githubURL)
+				setenv(t, "ACTIONS_ID_TOKEN_REQUEST_TOKEN", "request-token")
+			} else {
+				setenv(t, "ACTIONS_ID_TOKEN_REQUEST_URL", "")
+			}
+			p := &ambient.AmbientCredentialProvider{
+				Client:   fakeMetadataClient(tt.responses, tt.check),
+				Audience: "registry.example.com",
+			}
+			got, err := p.Token(context.Background())
+			if err != nil {
+				t.Fatalf("Token() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Token() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAmbientCredentialProviderNoCredentials(t *testing.T) {
+	setenv(t, "ACTIONS_ID_TOKEN_REQUEST_URL", "")
+	p := &ambient.AmbientCredentialProvider{
+		Client: fakeMetadataClient(nil, nil),
+	}
+	if _, err := p.Token(context.Background()); !errors.Is(err, ambient.ErrNoAmbientCredentials) {
+		t.Errorf("Token() error = %v, want %v", err, ambient.ErrNoAmbientCredentials)
+	}
+}
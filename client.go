@@ -0,0 +1,110 @@
+package notary
+
+import (
+	"context"
+	"errors"
+
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// NotaryV2Client signs and verifies manifests stored in a signature repository
+type NotaryV2Client struct {
+	repo    SignatureRepository
+	service SigningService
+	scope   *ScopeValidator
+}
+
+// manifestDeleter is implemented by signature repositories which can delete
+// the artifacts linking signatures to manifests
+type manifestDeleter interface {
+	DeleteManifest(ctx context.Context, d digest.Digest) error
+}
+
+// annotatedSigner is implemented by signing services which return annotations
+// to be attached to the signature descriptor, e.g. FallbackSigner
+type annotatedSigner interface {
+	SignWithAnnotations(ctx context.Context, desc oci.Descriptor, opts ...SignOption) ([]byte, map[string]string, error)
+}
+
+// signWithAnnotations signs with the service, and returns its annotations if
+// it provides any.
+func signWithAnnotations(ctx context.Context, service SigningService, desc oci.Descriptor, opts ...SignOption) ([]byte, map[string]string, error) {
+	if signer, ok := service.(annotatedSigner); ok {
+		return signer.SignWithAnnotations(ctx, desc, opts...)
+	}
+	sig, err := service.Sign(ctx, desc, opts...)
+	return sig, nil, err
+}
+
+// ClientOption configures the client.
+type ClientOption func(*NotaryV2Client)
+
+// WithAllowedScopes restricts signing to the repositories matching the glob
+// patterns. Both the signed references and the repository of the client must
+// be in scope. See ScopeValidator.
+func WithAllowedScopes(name string, scopes ...string) ClientOption {
+	return func(c *NotaryV2Client) {
+		c.scope = &ScopeValidator{
+			Service:       c.service,
+			Name:          name,
+			AllowedScopes: scopes,
+		}
+		c.service = c.scope
+	}
+}
+
+// NewClient creates a client signing and verifying with the signing service,
+// and storing the signatures in the repository.
+func NewClient(repo SignatureRepository, service SigningService, opts ...ClientOption) *NotaryV2Client {
+	c := &NotaryV2Client{
+		repo:    repo,
+		service: service,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Sign signs the subject manifest, uploads the signature, and links it to the
+// subject. The annotations returned by the signing service, e.g. those of a
+// FallbackSigner, are kept in the linking artifact, whose descriptor is
+// returned.
+func (c *NotaryV2Client) Sign(ctx context.Context, subject oci.Descriptor, opts ...SignOption) (oci.Descriptor, error) {
+	if c.scope != nil {
+		if err := c.scope.CheckRepository(c.repo); err != nil {
+			return oci.Descriptor{}, err
+		}
+	}
+	sig, annotations, err := signWithAnnotations(ctx, c.service, subject, opts...)
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+	signature, err := c.repo.Put(ctx, sig)
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+	signature.Annotations = annotations
+	return c.repo.Link(ctx, subject, signature)
+}
+
+// Verify verifies that the subject manifest has a valid signature, and returns
+// the references it is signed for.
+func (c *NotaryV2Client) Verify(ctx context.Context, subject oci.Descriptor) ([]string, error) {
+	_, references, err := VerifyWithExitCode(ctx, c.repo, c.service, subject, "")
+	return references, err
+}
+
+// Revoke revokes the signature linked by the artifact with the digest, as
+// returned by Sign, by deleting the artifact from the repository so that the
+// signature is no longer found on verification. The signature blob is left
+// to the garbage collection of the registry, and copies of the signature
+// kept elsewhere remain valid.
+func (c *NotaryV2Client) Revoke(ctx context.Context, artifactDigest digest.Digest) error {
+	deleter, ok := c.repo.(manifestDeleter)
+	if !ok {
+		return errors.New("revoke: the repository does not support deleting signatures")
+	}
+	return deleter.DeleteManifest(ctx, artifactDigest)
+}
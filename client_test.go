@@ -0,0 +1,75 @@
+package notary_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/registry"
+	"github.com/notaryproject/notary/v2/registry/registrytest"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestNotaryV2ClientRevoke(t *testing.T) {
+	ctx := context.Background()
+	server := registrytest.NewServer()
+	defer server.Close()
+	repo := registry.NewRepository(http.DefaultTransport, server.Host(), "test/app", true)
+	client := notary.NewClient(repo, newTestSigningService(t, "signer"))
+	subject := oci.Descriptor{
+		MediaType: oci.MediaTypeImageManifest,
+		Digest:    digest.FromString("app"),
+		Size:      3,
+	}
+
+	artifact, err := client.Sign(ctx, subject)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if _, err := client.Verify(ctx, subject); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if err := client.Revoke(ctx, artifact.Digest); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if _, err := client.Verify(ctx, subject); err == nil {
+		t.Fatal("Verify() of a revoked signature succeeded")
+	}
+	if err := client.Revoke(ctx, artifact.Digest); err == nil {
+		t.Fatal("Revoke() of a deleted artifact succeeded")
+	}
+}
+
+func TestNotaryV2ClientSignWithFallback(t *testing.T) {
+	ctx := context.Background()
+	server := registrytest.NewServer()
+	defer server.Close()
+	repo := registry.NewRepository(http.DefaultTransport, server.Host(), "test/app", true)
+	signer := &notary.FallbackSigner{
+		Primary:  &fakeSigningService{err: notary.ErrSignerUnavailable},
+		Fallback: newTestSigningService(t, "fallback"),
+	}
+	client := notary.NewClient(repo, signer)
+	subject := oci.Descriptor{
+		MediaType: oci.MediaTypeImageManifest,
+		Digest:    digest.FromString("app"),
+		Size:      3,
+	}
+
+	artifactDesc, err := client.Sign(ctx, subject)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	refs, err := repo.Lookup(ctx, subject.Digest)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if len(refs) != 1 || refs[0].ArtifactDescriptor.Digest != artifactDesc.Digest {
+		t.Fatalf("Lookup() = %v, want the artifact %v", refs, artifactDesc.Digest)
+	}
+	if got := refs[0].SignatureBlobDescriptor.Annotations[notary.AnnotationSigningMethod]; got != notary.SigningMethodFallback {
+		t.Errorf("annotation %s = %q, want %q", notary.AnnotationSigningMethod, got, notary.SigningMethodFallback)
+	}
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/registry"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// MediaTypeConfigArtifact specifies the media type for the config of the
+// manifest storing a configuration artifact.
+const MediaTypeConfigArtifact = "application/vnd.cncf.notary.config.artifact.v1+json"
+
+// emptyConfig is the config blob of the manifest storing a configuration
+// artifact
+var emptyConfig = []byte("{}")
+
+// ConfigArtifact is a versioned configuration file, e.g. Kubernetes manifests
+// or Terraform files, stored in a registry.
+type ConfigArtifact struct {
+	Version   string
+	Content   []byte
+	MediaType string
+}
+
+// ConfigSigner pushes signed configuration artifacts to registries
+type ConfigSigner struct {
+	Service   notary.SigningService
+	Transport http.RoundTripper
+	PlainHTTP bool
+}
+
+// Push pushes the configuration to the repository in the form of
+// registry/repository, tagged with its version. The manifest is signed with
+// the tagged reference, and the signature is linked to the manifest.
+func (s *ConfigSigner) Push(ctx context.Context, reference string, cfg ConfigArtifact) (oci.Descriptor, error) {
+	if cfg.Version == "" {
+		return oci.Descriptor{}, errors.New("missing config version")
+	}
+	repo, err := newRepository(s.Transport, reference, s.PlainHTTP)
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+
+	config := registry.DescriptorFromBytes(emptyConfig)
+	config.MediaType = MediaTypeConfigArtifact
+	if _, err := repo.PutBlob(ctx, emptyConfig); err != nil {
+		return oci.Descriptor{}, err
+	}
+	content := registry.DescriptorFromBytes(cfg.Content)
+	content.MediaType = cfg.MediaType
+	if _, err := repo.PutBlob(ctx, cfg.Content); err != nil {
+		return oci.Descriptor{}, err
+	}
+	manifestJSON, err := json.Marshal(oci.Manifest{
+		Versioned: specs.Versioned{
+			SchemaVersion: 2,
+		},
+		Config: config,
+		Layers: []oci.Descriptor{content},
+		Annotations: map[string]string{
+			oci.AnnotationVersion: cfg.Version,
+		},
+	})
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+	manifest, err := repo.PutTaggedManifest(ctx, manifestJSON, oci.MediaTypeImageManifest, cfg.Version)
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+
+	sig, err := s.Service.Sign(ctx, manifest, notary.WithReferences(taggedReference(reference, cfg.Version)))
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+	signature, err := repo.Put(ctx, sig)
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+	if _, err := repo.Link(ctx, manifest, signature); err != nil {
+		return oci.Descriptor{}, err
+	}
+	return manifest, nil
+}
+
+// ConfigVerifier retrieves signed configuration artifacts from registries
+type ConfigVerifier struct {
+	Service   notary.SigningService
+	Transport http.RoundTripper
+	PlainHTTP bool
+}
+
+// Get retrieves the given version of the configuration from the repository in
+// the form of registry/repository, after verifying that its manifest is signed
+// for that version. A signature for another version of the same manifest is
+// rejected.
+func (v *ConfigVerifier) Get(ctx context.Context, reference, version string) (ConfigArtifact, error) {
+	repo, err := newRepository(v.Transport, reference, v.PlainHTTP)
+	if err != nil {
+		return ConfigArtifact{}, err
+	}
+	manifest, err := repo.Resolve(ctx, version)
+	if err != nil {
+		return ConfigArtifact{}, err
+	}
+	if err := v.verify(ctx, repo, manifest, taggedReference(reference, version)); err != nil {
+		return ConfigArtifact{}, err
+	}
+
+	manifestJSON, mediaType, err := repo.GetManifest(ctx, manifest.Digest)
+	if err != nil {
+		return ConfigArtifact{}, err
+	}
+	if mediaType != oci.MediaTypeImageManifest {
+		return ConfigArtifact{}, fmt.Errorf("%s:%s is not a config artifact", reference, version)
+	}
+	var content oci.Manifest
+	if err := json.Unmarshal(manifestJSON, &content); err != nil {
+		return ConfigArtifact{}, err
+	}
+	if content.Config.MediaType != MediaTypeConfigArtifact || len(content.Layers) != 1 {
+		return ConfigArtifact{}, fmt.Errorf("%s:%s is not a config artifact", reference, version)
+	}
+	layer := content.Layers[0]
+	blob, err := repo.Get(ctx, layer.Digest)
+	if err != nil {
+		return ConfigArtifact{}, err
+	}
+	return ConfigArtifact{
+		Version:   version,
+		Content:   blob,
+		MediaType: layer.MediaType,
+	}, nil
+}
+
+// verify verifies that the manifest is signed for the reference
+func (v *ConfigVerifier) verify(ctx context.Context, repo *registry.Repository, manifest oci.Descriptor, reference string) error {
+	switch code, _, err := notary.VerifyWithExitCode(ctx, repo, v.Service, manifest, reference); code {
+	case notary.ExitValid:
+		return nil
+	case notary.ExitInvalid, notary.ExitPolicyViolation:
+		return fmt.Errorf("no valid signature found for %s: %w", reference, err)
+	default:
+		return err
+	}
+}
+
+// newRepository creates a client to the repository in the form of
+// registry/repository
+func newRepository(tr http.RoundTripper, reference string, plainHTTP bool) (*registry.Repository, error) {
+	parts := strings.SplitN(reference, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid config reference: %s", reference)
+	}
+	return registry.NewRepository(tr, parts[0], parts[1], plainHTTP), nil
+}
+
+func taggedReference(reference, version string) string {
+	return reference + ":" + version
+}
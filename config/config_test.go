@@ -0,0 +1,137 @@
+package config_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/libtrust"
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/config"
+	"github.com/notaryproject/notary/v2/registry"
+	"github.com/notaryproject/notary/v2/registry/registrytest"
+	"github.com/notaryproject/notary/v2/simple"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// newTestSigningService creates a signing service with a P-256 key and a
+// self-signed certificate for the fake registry on the loopback address
+func newTestSigningService(t *testing.T) notary.SigningService {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "signer"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingKey, err := libtrust.FromCryptoPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service, err := simple.NewSigningService(signingKey, []*x509.Certificate{cert}, []*x509.Certificate{cert}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service
+}
+
+func TestConfigPushGet(t *testing.T) {
+	ctx := context.Background()
+	server := registrytest.NewServer()
+	defer server.Close()
+	service := newTestSigningService(t)
+	signer := &config.ConfigSigner{Service: service, Transport: http.DefaultTransport, PlainHTTP: true}
+	verifier := &config.ConfigVerifier{Service: service, Transport: http.DefaultTransport, PlainHTTP: true}
+	reference := server.Host() + "/configs/app"
+
+	cfg := config.ConfigArtifact{
+		Version:   "v1",
+		Content:   []byte("replicas: 3"),
+		MediaType: "application/yaml",
+	}
+	if _, err := signer.Push(ctx, reference, cfg); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	got, err := verifier.Get(ctx, reference, "v1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Version != cfg.Version || !bytes.Equal(got.Content, cfg.Content) || got.MediaType != cfg.MediaType {
+		t.Errorf("Get() = %+v, want %+v", got, cfg)
+	}
+}
+
+func TestConfigGetVersionSwap(t *testing.T) {
+	ctx := context.Background()
+	server := registrytest.NewServer()
+	defer server.Close()
+	service := newTestSigningService(t)
+	signer := &config.ConfigSigner{Service: service, Transport: http.DefaultTransport, PlainHTTP: true}
+	verifier := &config.ConfigVerifier{Service: service, Transport: http.DefaultTransport, PlainHTTP: true}
+	reference := server.Host() + "/configs/app"
+
+	manifest, err := signer.Push(ctx, reference, config.ConfigArtifact{
+		Version:   "v1",
+		Content:   []byte("replicas: 3"),
+		MediaType: "application/yaml",
+	})
+	if err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	// the manifest signed for v1 is tagged as v2
+	repo := registry.NewRepository(http.DefaultTransport, server.Host(), "configs/app", true)
+	manifestJSON, _, err := repo.GetManifest(ctx, manifest.Digest)
+	if err != nil {
+		t.Fatalf("GetManifest() error = %v", err)
+	}
+	if _, err := repo.PutTaggedManifest(ctx, manifestJSON, oci.MediaTypeImageManifest, "v2"); err != nil {
+		t.Fatalf("PutTaggedManifest() error = %v", err)
+	}
+	if _, err := verifier.Get(ctx, reference, "v2"); err == nil || !strings.Contains(err.Error(), "no valid signature") {
+		t.Errorf("Get() of another version error = %v, want no valid signature", err)
+	}
+}
+
+func TestConfigPushInvalid(t *testing.T) {
+	signer := &config.ConfigSigner{Service: newTestSigningService(t), Transport: http.DefaultTransport, PlainHTTP: true}
+	for _, tt := range []struct {
+		name      string
+		reference string
+		cfg       config.ConfigArtifact
+	}{
+		{name: "missing version", reference: "registry.example.com/configs/app", cfg: config.ConfigArtifact{Content: []byte("{}")}},
+		{name: "missing repository", reference: "registry.example.com", cfg: config.ConfigArtifact{Version: "v1"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := signer.Push(context.Background(), tt.reference, tt.cfg); err == nil {
+				t.Error("Push() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package notary
+
+import (
+	"context"
+	"crypto/x509"
+
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Verifier verifies signatures, which is implemented by every SigningService.
+type Verifier interface {
+	Verify(ctx context.Context, desc oci.Descriptor, signature []byte) ([]string, error)
+}
+
+// contextKey is the type of the keys of the values carried by contexts, which
+// avoids collisions with the keys of other packages.
+type contextKey int
+
+const (
+	verifierKey contextKey = iota
+	trustStoreKey
+	replayCheckKey
+)
+
+// WithVerifier returns a copy of ctx carrying the verifier, which is used by
+// the verification functions called with ctx if no verifier is passed
+// explicitly.
+func WithVerifier(ctx context.Context, v Verifier) context.Context {
+	return context.WithValue(ctx, verifierKey, v)
+}
+
+// VerifierFromContext returns the verifier carried by ctx, if any.
+func VerifierFromContext(ctx context.Context) (Verifier, bool) {
+	v, ok := ctx.Value(verifierKey).(Verifier)
+	return v, ok
+}
+
+// WithTrustStore returns a copy of ctx carrying the trusted root certificates,
+// which are used by the verifiers called with ctx in place of the roots they
+// are configured with.
+func WithTrustStore(ctx context.Context, roots *x509.CertPool) context.Context {
+	return context.WithValue(ctx, trustStoreKey, roots)
+}
+
+// TrustStoreFromContext returns the trusted root certificates carried by ctx,
+// if any.
+func TrustStoreFromContext(ctx context.Context) (*x509.CertPool, bool) {
+	roots, ok := ctx.Value(trustStoreKey).(*x509.CertPool)
+	return roots, ok
+}
+
+// WithReplayCheck returns a copy of ctx requesting the verifiers to accept
+// each signature only once, by recording its nonce. Verifiers without a nonce
+// registry fail the verification.
+func WithReplayCheck(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replayCheckKey, true)
+}
+
+// ReplayCheckFromContext tells whether ctx requests a replay check
+func ReplayCheckFromContext(ctx context.Context) bool {
+	check, _ := ctx.Value(replayCheckKey).(bool)
+	return check
+}
@@ -0,0 +1,91 @@
+package notary
+
+import (
+	"context"
+
+	x509nv2 "github.com/notaryproject/notary/v2/signature/x509"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// SignatureDiff describes the difference of signers between two manifests.
+// Signers are identified by the fingerprint of their signing certificate, so
+// that a signer re-signing a new version of an image is reported in InBoth
+// even though the signature blobs differ.
+type SignatureDiff struct {
+	// OnlyInOld contains the signatures of the old manifest by signers who
+	// did not sign the new manifest
+	OnlyInOld []SignatureRef
+
+	// OnlyInNew contains the signatures of the new manifest by signers who
+	// did not sign the old manifest
+	OnlyInNew []SignatureRef
+
+	// InBoth contains the signatures of the new manifest by signers who also
+	// signed the old manifest
+	InBoth []SignatureRef
+}
+
+// DiffSignatures compares the signers of two manifests, typically two
+// versions of the same image, so that regressions in signing coverage can be
+// detected.
+// The signatures are downloaded to read the signing certificates but are NOT
+// verified. Signatures without an x509 signing certificate are identified by
+// their blob digest.
+func DiffSignatures(ctx context.Context, repo SignatureRepository, oldSubject, newSubject oci.Descriptor) (SignatureDiff, error) {
+	oldRefs, oldSigners, err := lookupSigners(ctx, repo, oldSubject.Digest)
+	if err != nil {
+		return SignatureDiff{}, err
+	}
+	newRefs, newSigners, err := lookupSigners(ctx, repo, newSubject.Digest)
+	if err != nil {
+		return SignatureDiff{}, err
+	}
+
+	signedOld := make(map[string]bool, len(oldSigners))
+	for _, signer := range oldSigners {
+		signedOld[signer] = true
+	}
+	signedNew := make(map[string]bool, len(newSigners))
+	for _, signer := range newSigners {
+		signedNew[signer] = true
+	}
+
+	var diff SignatureDiff
+	for i, ref := range oldRefs {
+		if !signedNew[oldSigners[i]] {
+			diff.OnlyInOld = append(diff.OnlyInOld, ref)
+		}
+	}
+	for i, ref := range newRefs {
+		if signedOld[newSigners[i]] {
+			diff.InBoth = append(diff.InBoth, ref)
+		} else {
+			diff.OnlyInNew = append(diff.OnlyInNew, ref)
+		}
+	}
+	return diff, nil
+}
+
+// lookupSigners finds the signatures of the manifest with the identity of
+// the signer of each signature.
+func lookupSigners(ctx context.Context, repo SignatureRepository, manifestDigest digest.Digest) ([]SignatureRef, []string, error) {
+	signatures, err := repo.LookupSet(ctx, manifestDigest)
+	if err != nil {
+		return nil, nil, err
+	}
+	refs := signatures.ToSlice()
+	signers := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		sig, err := repo.Get(ctx, ref.SignatureBlobDescriptor.Digest)
+		if err != nil {
+			return nil, nil, err
+		}
+		signer, err := x509nv2.SignerFingerprint(sig)
+		if err != nil {
+			signer = ref.SignatureBlobDescriptor.Digest.String()
+		}
+		signers = append(signers, signer)
+	}
+	return refs, signers, nil
+}
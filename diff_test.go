@@ -0,0 +1,87 @@
+package notary_test
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+	"testing"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/registry"
+	"github.com/notaryproject/notary/v2/registry/registrytest"
+	"github.com/notaryproject/notary/v2/simple"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// signAndLink signs the subject with a new service of the given signer and
+// links the signature to the subject in the repository.
+func signAndLink(t *testing.T, repo notary.SignatureRepository, service notary.SigningService, subject oci.Descriptor) oci.Descriptor {
+	t.Helper()
+	ctx := context.Background()
+	sig, err := service.Sign(ctx, subject)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	sigDesc, err := repo.Put(ctx, sig)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, err := repo.Link(ctx, subject, sigDesc); err != nil {
+		t.Fatalf("Link() error = %v", err)
+	}
+	return sigDesc
+}
+
+func newTestSigningService(t *testing.T, commonName string) notary.SigningService {
+	t.Helper()
+	key, cert := newTestKeyPair(t, commonName)
+	service, err := simple.NewSigningService(key, []*x509.Certificate{cert}, []*x509.Certificate{cert}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service
+}
+
+func TestDiffSignatures(t *testing.T) {
+	server := registrytest.NewServer()
+	defer server.Close()
+	repo := registry.NewRepository(http.DefaultTransport, server.Host(), "test/app", true)
+
+	oldSubject := oci.Descriptor{
+		MediaType: oci.MediaTypeImageManifest,
+		Digest:    digest.FromString("v1"),
+		Size:      2,
+	}
+	newSubject := oci.Descriptor{
+		MediaType: oci.MediaTypeImageManifest,
+		Digest:    digest.FromString("v2"),
+		Size:      2,
+	}
+	both := newTestSigningService(t, "both")
+	oldOnly := newTestSigningService(t, "old")
+	newOnly := newTestSigningService(t, "new")
+
+	signAndLink(t, repo, both, oldSubject)
+	bothNew := signAndLink(t, repo, both, newSubject)
+	oldOnlySig := signAndLink(t, repo, oldOnly, oldSubject)
+	newOnlySig := signAndLink(t, repo, newOnly, newSubject)
+
+	diff, err := notary.DiffSignatures(context.Background(), repo, oldSubject, newSubject)
+	if err != nil {
+		t.Fatalf("DiffSignatures() error = %v", err)
+	}
+	for _, tt := range []struct {
+		name string
+		got  []notary.SignatureRef
+		want digest.Digest
+	}{
+		{name: "InBoth", got: diff.InBoth, want: bothNew.Digest},
+		{name: "OnlyInOld", got: diff.OnlyInOld, want: oldOnlySig.Digest},
+		{name: "OnlyInNew", got: diff.OnlyInNew, want: newOnlySig.Digest},
+	} {
+		if len(tt.got) != 1 || tt.got[0].SignatureBlobDescriptor.Digest != tt.want {
+			t.Errorf("%s = %v, want only the signature %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
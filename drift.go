@@ -0,0 +1,108 @@
+package notary
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// defaultDriftCheckInterval is the interval between checks of a DriftDetector
+// without CheckInterval
+const defaultDriftCheckInterval = time.Minute
+
+// DriftEvent reports the signatures of a subject found in the replica of
+// Region1 but missing in the replica of Region2.
+type DriftEvent struct {
+	Subject        digest.Digest
+	Region1        string
+	Region2        string
+	MissingDigests []digest.Digest
+}
+
+// DriftDetector watches the signatures of subjects across registry replicas,
+// e.g. to detect replication lag in geo-distributed registries.
+type DriftDetector struct {
+	// Replicas are the repository replicas by region
+	Replicas map[string]SignatureRepository
+
+	// Subjects are the digests of the watched subjects
+	Subjects []digest.Digest
+
+	// CheckInterval is the interval between checks. It defaults to one minute
+	// if not positive.
+	CheckInterval time.Duration
+}
+
+// Run checks the replicas every CheckInterval, and sends the drift events to
+// events until ctx is done. Failed checks are retried on the next interval.
+func (d *DriftDetector) Run(ctx context.Context, events chan<- DriftEvent) error {
+	interval := d.CheckInterval
+	if interval <= 0 {
+		interval = defaultDriftCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if drifts, err := d.Check(ctx); err == nil {
+			for _, event := range drifts {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Check compares the signatures of the subjects across the replicas once.
+// For each pair of replicas disagreeing on a subject, an event is returned for
+// each direction with missing signatures.
+func (d *DriftDetector) Check(ctx context.Context) ([]DriftEvent, error) {
+	regions := make([]string, 0, len(d.Replicas))
+	for region := range d.Replicas {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	var events []DriftEvent
+	for _, subject := range d.Subjects {
+		sets := make(map[string]*SignatureSet, len(regions))
+		for _, region := range regions {
+			set, err := d.Replicas[region].LookupSet(ctx, subject)
+			if err != nil {
+				return nil, err
+			}
+			sets[region] = set
+		}
+		for _, region1 := range regions {
+			for _, region2 := range regions {
+				if region1 == region2 {
+					continue
+				}
+				missing := sets[region1].Difference(sets[region2])
+				if missing.Len() == 0 {
+					continue
+				}
+				var digests []digest.Digest
+				for _, ref := range missing.ToSlice() {
+					digests = append(digests, ref.SignatureBlobDescriptor.Digest)
+				}
+				events = append(events, DriftEvent{
+					Subject:        subject,
+					Region1:        region1,
+					Region2:        region2,
+					MissingDigests: digests,
+				})
+			}
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,62 @@
+package notary_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/registry"
+	"github.com/notaryproject/notary/v2/registry/registrytest"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestDriftDetectorRun(t *testing.T) {
+	replicas := make(map[string]notary.SignatureRepository)
+	for _, region := range []string{"east", "west"} {
+		server := registrytest.NewServer()
+		defer server.Close()
+		replicas[region] = registry.NewRepository(http.DefaultTransport, server.Host(), "test/app", true)
+	}
+	subject := oci.Descriptor{
+		MediaType: oci.MediaTypeImageManifest,
+		Digest:    digest.FromString("app"),
+		Size:      3,
+	}
+	sigDesc := signAndLink(t, replicas["east"], newTestSigningService(t, "signer"), subject)
+
+	// a zero CheckInterval falls back to the default
+	detector := &notary.DriftDetector{
+		Replicas: replicas,
+		Subjects: []digest.Digest{subject.Digest},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan notary.DriftEvent)
+	done := make(chan error, 1)
+	go func() {
+		done <- detector.Run(ctx, events)
+	}()
+
+	select {
+	case event := <-events:
+		if event.Region1 != "east" || event.Region2 != "west" || len(event.MissingDigests) != 1 || event.MissingDigests[0] != sigDesc.Digest {
+			t.Fatalf("Run() event = %+v, want the signature missing in west", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() sent no event")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Run() error = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not return after cancel")
+	}
+}
@@ -0,0 +1,114 @@
+package notary_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/docker/libtrust"
+	"github.com/notaryproject/notary/v2/registry"
+	"github.com/notaryproject/notary/v2/registry/registrytest"
+	"github.com/notaryproject/notary/v2/simple"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// newTestKeyPair generates a P-256 key with a self-signed certificate
+func newTestKeyPair(t *testing.T, commonName string) (libtrust.PrivateKey, *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		DNSNames:              []string{"registry.example.com"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingKey, err := libtrust.FromCryptoPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signingKey, cert
+}
+
+func TestE2E_SignVerifyECDSA_P256(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opts []registry.RepositoryOption
+	}{
+		{name: "artifacts extension"},
+		{name: "referrers API", opts: []registry.RepositoryOption{registry.WithReferrersAPI()}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := registrytest.NewServer()
+			defer server.Close()
+			repo := registry.NewRepository(http.DefaultTransport, server.Host(), "test/app", true, tt.opts...)
+
+			key, cert := newTestKeyPair(t, "signer")
+			service, err := simple.NewSigningService(key, []*x509.Certificate{cert}, []*x509.Certificate{cert}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.Background()
+			manifest := []byte(`{"schemaVersion":2}`)
+			subject := oci.Descriptor{
+				MediaType: oci.MediaTypeImageManifest,
+				Digest:    digest.FromBytes(manifest),
+				Size:      int64(len(manifest)),
+			}
+			sig, err := service.Sign(ctx, subject)
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			sigDesc, err := repo.Put(ctx, sig)
+			if err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+			if _, err := repo.Link(ctx, subject, sigDesc); err != nil {
+				t.Fatalf("Link() error = %v", err)
+			}
+
+			refs, err := repo.Lookup(ctx, subject.Digest)
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+			if len(refs) != 1 || refs[0].SignatureBlobDescriptor.Digest != sigDesc.Digest {
+				t.Fatalf("Lookup() = %v, want the signature %v", refs, sigDesc.Digest)
+			}
+			fetched, err := repo.Get(ctx, refs[0].SignatureBlobDescriptor.Digest)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if _, err := service.Verify(ctx, subject, fetched); err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+
+			other := subject
+			other.Digest = digest.FromString("other")
+			if _, err := service.Verify(ctx, other, fetched); err == nil {
+				t.Fatal("Verify() of another manifest succeeded")
+			}
+		})
+	}
+}
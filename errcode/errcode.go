@@ -0,0 +1,53 @@
+package errcode
+
+// ErrorCode classifies the errors returned by notary. The codes and the base
+// error are defined apart from the notary package, so that the packages it
+// imports, e.g. signature, return them too.
+type ErrorCode string
+
+// Error codes
+const (
+	NotFound        ErrorCode = "NOT_FOUND"
+	Unauthorized    ErrorCode = "UNAUTHORIZED"
+	DigestMismatch  ErrorCode = "DIGEST_MISMATCH"
+	InvalidFormat   ErrorCode = "INVALID_FORMAT"
+	PolicyViolation ErrorCode = "POLICY_VIOLATION"
+	Expired         ErrorCode = "EXPIRED"
+	NotYetValid     ErrorCode = "NOT_YET_VALID"
+	Replayed        ErrorCode = "REPLAYED"
+	Network         ErrorCode = "NETWORK"
+	PartialResult   ErrorCode = "PARTIAL_RESULT"
+	QuotaExceeded   ErrorCode = "QUOTA_EXCEEDED"
+)
+
+// NotaryError is the base of the errors returned by notary, which is embedded
+// by the specific error types so that they can be handled uniformly by
+// errors.As.
+type NotaryError struct {
+	Code  ErrorCode
+	Op    string
+	Cause error
+}
+
+func (e *NotaryError) Error() string {
+	msg := string(e.Code)
+	if e.Op != "" {
+		msg = e.Op + ": " + msg
+	}
+	if e.Cause != nil {
+		msg += ": " + e.Cause.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the cause of the error
+func (e *NotaryError) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether target is a sentinel NotaryError of the same code,
+// i.e. one with a code only. Other targets are matched by identity.
+func (e *NotaryError) Is(target error) bool {
+	t, ok := target.(*NotaryError)
+	return ok && t.Code != "" && t.Code == e.Code && t.Op == "" && t.Cause == nil
+}
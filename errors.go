@@ -0,0 +1,58 @@
+package notary
+
+import (
+	"fmt"
+
+	"github.com/notaryproject/notary/v2/errcode"
+	"github.com/opencontainers/go-digest"
+)
+
+// ErrorCode classifies the errors returned by notary
+type ErrorCode = errcode.ErrorCode
+
+// Error codes
+const (
+	ErrCodeNotFound        = errcode.NotFound
+	ErrCodeUnauthorized    = errcode.Unauthorized
+	ErrCodeDigestMismatch  = errcode.DigestMismatch
+	ErrCodeInvalidFormat   = errcode.InvalidFormat
+	ErrCodePolicyViolation = errcode.PolicyViolation
+	ErrCodeExpired         = errcode.Expired
+	ErrCodeNotYetValid     = errcode.NotYetValid
+	ErrCodeReplayed        = errcode.Replayed
+	ErrCodeNetwork         = errcode.Network
+	ErrCodePartialResult   = errcode.PartialResult
+	ErrCodeQuotaExceeded   = errcode.QuotaExceeded
+)
+
+// Sentinel errors to be matched by errors.Is against any error of the same
+// code, regardless of its operation and cause.
+var (
+	ErrNotFound        = &NotaryError{Code: ErrCodeNotFound}
+	ErrUnauthorized    = &NotaryError{Code: ErrCodeUnauthorized}
+	ErrDigestMismatch  = &NotaryError{Code: ErrCodeDigestMismatch}
+	ErrInvalidFormat   = &NotaryError{Code: ErrCodeInvalidFormat}
+	ErrPolicyViolation = &NotaryError{Code: ErrCodePolicyViolation}
+	ErrExpired         = &NotaryError{Code: ErrCodeExpired}
+	ErrNotYetValid     = &NotaryError{Code: ErrCodeNotYetValid}
+	ErrReplayed        = &NotaryError{Code: ErrCodeReplayed}
+	ErrNetwork         = &NotaryError{Code: ErrCodeNetwork}
+	ErrPartialResult   = &NotaryError{Code: ErrCodePartialResult}
+	ErrQuotaExceeded   = &NotaryError{Code: ErrCodeQuotaExceeded}
+)
+
+// NotaryError is the base of the errors returned by notary, which is embedded
+// by the specific error types so that they can be handled uniformly by
+// errors.As.
+type NotaryError = errcode.NotaryError
+
+// PartialResultError is returned when an operation on multiple subjects
+// fails for some of them.
+type PartialResultError struct {
+	NotaryError
+	Errors map[digest.Digest]error
+}
+
+func (e *PartialResultError) Error() string {
+	return fmt.Sprintf("failed on %d subject(s)", len(e.Errors))
+}
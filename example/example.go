@@ -44,7 +44,7 @@ func main() {
 	fmt.Println(manifestDescriptor)
 
 	fmt.Println(">>> Sign manifest")
-	sig, err := signing.Sign(ctx, manifestDescriptor, references...)
+	sig, err := signing.Sign(ctx, manifestDescriptor, notary.WithReferences(references...))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -71,15 +71,16 @@ func main() {
 	fmt.Println(artifactDescriptor.Digest)
 
 	fmt.Println(">>> Lookup signatures")
-	signatureDigests, err := client.Lookup(ctx, manifestDescriptor.Digest)
+	signatures, err := client.Lookup(ctx, manifestDescriptor.Digest)
 	if err != nil {
 		log.Fatal(err)
 	}
-	for _, signatureDigest := range signatureDigests {
-		fmt.Println("-", signatureDigest)
+	for _, signature := range signatures {
+		fmt.Println("-", signature.SignatureBlobDescriptor.Digest)
 	}
 
-	for _, signatureDigest := range signatureDigests {
+	for _, signature := range signatures {
+		signatureDigest := signature.SignatureBlobDescriptor.Digest
 		fmt.Println(">>> Get signature:", signatureDigest)
 		sig, err := client.Get(ctx, signatureDigest)
 		if err != nil {
@@ -0,0 +1,82 @@
+package notary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// ExitCode is the exit code of the notary verify command
+type ExitCode int
+
+// Exit codes of the notary verify command
+const (
+	ExitValid ExitCode = iota
+	ExitInvalid
+	ExitNotFound
+	ExitPolicyViolation
+	ExitError
+)
+
+// VerifyWithExitCode verifies the signatures of the manifest following the
+// exit code convention of the notary verify command, so that a command line
+// tool can pass the exit code to os.Exit.
+// If reference is not empty, a valid signature must also be signed for the
+// reference, or ExitPolicyViolation is returned.
+// If verifier is nil, the verifier carried by ctx is used.
+// On success, the signed references are returned.
+func VerifyWithExitCode(ctx context.Context, repo SignatureRepository, verifier Verifier, manifest oci.Descriptor, reference string) (ExitCode, []string, error) {
+	if verifier == nil {
+		var ok bool
+		if verifier, ok = VerifierFromContext(ctx); !ok {
+			return ExitError, nil, errors.New("no verifier provided")
+		}
+	}
+	signatures, err := repo.Lookup(ctx, manifest.Digest)
+	if err != nil {
+		return exitCodeOf(err), nil, err
+	}
+	if len(signatures) == 0 {
+		return ExitNotFound, nil, errors.New("no signature found")
+	}
+
+	code := ExitInvalid
+	var lastErr error
+	for _, signature := range signatures {
+		sig, err := signature.Fetch(ctx, repo)
+		if err != nil {
+			return exitCodeOf(err), nil, err
+		}
+		references, err := verifier.Verify(ctx, manifest, sig)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if reference == "" || containsString(references, reference) {
+			return ExitValid, references, nil
+		}
+		code = ExitPolicyViolation
+		lastErr = fmt.Errorf("signature is not signed for %s", reference)
+	}
+	return code, nil, lastErr
+}
+
+// exitCodeOf returns the exit code of a failure to access the signatures,
+// which are not found if the registry replies 404.
+func exitCodeOf(err error) ExitCode {
+	if errors.Is(err, ErrNotFound) {
+		return ExitNotFound
+	}
+	return ExitError
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package notary_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/registry"
+	"github.com/notaryproject/notary/v2/registry/registrytest"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestVerifyWithExitCode(t *testing.T) {
+	service := newTestSigningService(t, "signer")
+	subject := oci.Descriptor{
+		MediaType: oci.MediaTypeImageManifest,
+		Digest:    digest.FromString("app"),
+		Size:      3,
+	}
+	reference := "registry.example.com/test/app:v1"
+	for _, tt := range []struct {
+		name       string
+		serverOpts []registrytest.Option
+		sign       bool
+		verifier   notary.Verifier
+		reference  string
+		closed     bool
+		want       notary.ExitCode
+	}{
+		{name: "valid", sign: true, reference: reference, want: notary.ExitValid},
+		{name: "no signature", want: notary.ExitNotFound},
+		{
+			name:       "registry 404",
+			serverOpts: []registrytest.Option{registrytest.WithoutArtifactsExtension()},
+			want:       notary.ExitNotFound,
+		},
+		{name: "invalid", sign: true, verifier: newTestSigningService(t, "other"), want: notary.ExitInvalid},
+		{name: "policy violation", sign: true, reference: "registry.example.com/test/other:v1", want: notary.ExitPolicyViolation},
+		{name: "registry unreachable", closed: true, want: notary.ExitError},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server := registrytest.NewServer(tt.serverOpts...)
+			defer server.Close()
+			repo := registry.NewRepository(http.DefaultTransport, server.Host(), "test/app", true)
+			if tt.sign {
+				sig, err := service.Sign(ctx, subject, notary.WithReferences(reference))
+				if err != nil {
+					t.Fatalf("Sign() error = %v", err)
+				}
+				sigDesc, err := repo.Put(ctx, sig)
+				if err != nil {
+					t.Fatalf("Put() error = %v", err)
+				}
+				if _, err := repo.Link(ctx, subject, sigDesc); err != nil {
+					t.Fatalf("Link() error = %v", err)
+				}
+			}
+			if tt.closed {
+				server.Close()
+			}
+			verifier := tt.verifier
+			if verifier == nil {
+				verifier = service
+			}
+
+			code, _, err := notary.VerifyWithExitCode(ctx, repo, verifier, subject, tt.reference)
+			if code != tt.want {
+				t.Fatalf("VerifyWithExitCode() = %d, %v, want %d", code, err, tt.want)
+			}
+			if (err == nil) != (tt.want == notary.ExitValid) {
+				t.Errorf("VerifyWithExitCode() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package notary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/notaryproject/notary/v2/signature"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// VerifyWithExternalHash verifies the signature described by sigDesc for
+// content which is not stored in the registry, e.g. a large VM image kept in
+// object storage, given the digest of the content instead of the content.
+// The caller is responsible for computing subjectDigest over the actual
+// content, since the verification only proves that the signature covers that
+// digest.
+// If verifier is nil, the verifier carried by ctx is used.
+// On success, the references of the signed content are returned.
+func VerifyWithExternalHash(ctx context.Context, sigDesc oci.Descriptor, subjectDigest digest.Digest, repo SignatureRepository, verifier Verifier) ([]string, error) {
+	if verifier == nil {
+		var ok bool
+		if verifier, ok = VerifierFromContext(ctx); !ok {
+			return nil, errors.New("no verifier provided")
+		}
+	}
+	sig, err := repo.Get(ctx, sigDesc.Digest)
+	if err != nil {
+		return nil, err
+	}
+
+	signedDigest, err := signature.SignedContentDigest(sig)
+	if err != nil {
+		return nil, &NotaryError{
+			Code:  ErrCodeInvalidFormat,
+			Op:    "verify external",
+			Cause: err,
+		}
+	}
+	if signedDigest != subjectDigest {
+		return nil, &NotaryError{
+			Code:  ErrCodeDigestMismatch,
+			Op:    "verify external",
+			Cause: fmt.Errorf("signature subject %s does not match %s", signedDigest, subjectDigest),
+		}
+	}
+
+	// The signed descriptor is read from the payload before verification to
+	// recover the media type and size, which are checked by the verifier.
+	claims, err := signature.UnverifiedClaims(sig)
+	if err != nil {
+		return nil, &NotaryError{
+			Code:  ErrCodeInvalidFormat,
+			Op:    "verify external",
+			Cause: err,
+		}
+	}
+	return verifier.Verify(ctx, oci.Descriptor{
+		MediaType: claims.MediaType,
+		Digest:    subjectDigest,
+		Size:      claims.Size,
+	}, sig)
+}
@@ -0,0 +1,91 @@
+package notary
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net"
+
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// AnnotationSigningMethod is the signature annotation recording how the
+	// signature was produced.
+	AnnotationSigningMethod = "io.notary.signing.method"
+
+	// SigningMethodFallback marks signatures produced by a fallback signer.
+	SigningMethodFallback = "fallback"
+)
+
+// ErrSignerUnavailable can be wrapped by signing services to report that the
+// signing key is temporarily unavailable, e.g. the HSM is offline.
+var ErrSignerUnavailable = errors.New("signer unavailable")
+
+// FallbackSigner signs with the fallback signing service when the primary one
+// is unavailable. Signing is not retried if the primary signing service
+// rejects the request, but only on network errors and ErrSignerUnavailable,
+// and not once the context of the caller is done.
+type FallbackSigner struct {
+	Primary  SigningService
+	Fallback SigningService
+}
+
+// Sign signs with the primary signing service, or the fallback one if the
+// primary is unavailable.
+func (s *FallbackSigner) Sign(ctx context.Context, desc oci.Descriptor, opts ...SignOption) ([]byte, error) {
+	sig, _, err := s.SignWithAnnotations(ctx, desc, opts...)
+	return sig, err
+}
+
+// SignWithAnnotations signs like Sign, and also returns the annotations to be
+// attached to the signature descriptor on linking. Signatures produced by the
+// fallback signing service are annotated with AnnotationSigningMethod, so that
+// auditors can find and re-sign them once the primary is restored.
+func (s *FallbackSigner) SignWithAnnotations(ctx context.Context, desc oci.Descriptor, opts ...SignOption) ([]byte, map[string]string, error) {
+	sig, err := s.Primary.Sign(ctx, desc, opts...)
+	if err == nil || !isUnavailable(err) {
+		return sig, nil, err
+	}
+	// a timeout of the caller is no outage of the primary
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, nil, ctxErr
+	}
+	sig, err = s.Fallback.Sign(ctx, desc, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sig, map[string]string{
+		AnnotationSigningMethod: SigningMethodFallback,
+	}, nil
+}
+
+// Verify verifies the signature with the primary signing service, or the
+// fallback one if the signature is made with a key the primary does not know
+// or trust, as signatures of the fallback signing service are. Any other
+// failure, e.g. a signature not matching the content, is returned as is.
+func (s *FallbackSigner) Verify(ctx context.Context, desc oci.Descriptor, signature []byte) ([]string, error) {
+	references, err := s.Primary.Verify(ctx, desc, signature)
+	if err == nil || !isUnknownKey(err) {
+		return references, err
+	}
+	return s.Fallback.Verify(ctx, desc, signature)
+}
+
+// isUnknownKey reports whether the verification failed because the signing
+// key is not known or not trusted.
+func isUnknownKey(err error) bool {
+	if errors.Is(err, ErrNotFound) {
+		return true
+	}
+	var authorityErr x509.UnknownAuthorityError
+	return errors.As(err, &authorityErr)
+}
+
+func isUnavailable(err error) bool {
+	if errors.Is(err, ErrSignerUnavailable) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
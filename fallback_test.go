@@ -0,0 +1,149 @@
+package notary_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// fakeSigningService signs and verifies with fixed results
+type fakeSigningService struct {
+	sig       []byte
+	err       error
+	called    bool
+	verifyErr error
+	verified  bool
+}
+
+func (s *fakeSigningService) Sign(ctx context.Context, desc oci.Descriptor, opts ...notary.SignOption) ([]byte, error) {
+	s.called = true
+	return s.sig, s.err
+}
+
+func (s *fakeSigningService) Verify(ctx context.Context, desc oci.Descriptor, signature []byte) ([]string, error) {
+	s.verified = true
+	return nil, s.verifyErr
+}
+
+func TestFallbackSigner(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, tt := range []struct {
+		name           string
+		ctx            context.Context
+		primaryErr     error
+		wantSig        string
+		wantFallback   bool
+		wantAnnotation bool
+		wantErr        error
+	}{
+		{name: "primary", ctx: context.Background(), wantSig: "primary"},
+		{
+			name:           "primary unavailable",
+			ctx:            context.Background(),
+			primaryErr:     fmt.Errorf("hsm offline: %w", notary.ErrSignerUnavailable),
+			wantSig:        "fallback",
+			wantFallback:   true,
+			wantAnnotation: true,
+		},
+		{
+			name:           "network error",
+			ctx:            context.Background(),
+			primaryErr:     &net.OpError{Op: "dial", Err: errors.New("connection refused")},
+			wantSig:        "fallback",
+			wantFallback:   true,
+			wantAnnotation: true,
+		},
+		{
+			name:       "primary rejects",
+			ctx:        context.Background(),
+			primaryErr: errors.New("key not allowed"),
+		},
+		{
+			name:       "caller done",
+			ctx:        canceled,
+			primaryErr: &net.OpError{Op: "dial", Err: context.Canceled},
+			wantErr:    context.Canceled,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &fakeSigningService{sig: []byte("primary"), err: tt.primaryErr}
+			if tt.primaryErr != nil {
+				primary.sig = nil
+			}
+			fallback := &fakeSigningService{sig: []byte("fallback")}
+			signer := &notary.FallbackSigner{Primary: primary, Fallback: fallback}
+
+			sig, annotations, err := signer.SignWithAnnotations(tt.ctx, oci.Descriptor{})
+			if tt.wantSig == "" {
+				if err == nil {
+					t.Fatal("SignWithAnnotations() succeeded")
+				}
+			} else if err != nil {
+				t.Fatalf("SignWithAnnotations() error = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("SignWithAnnotations() error = %v, want %v", err, tt.wantErr)
+			}
+			if string(sig) != tt.wantSig {
+				t.Errorf("SignWithAnnotations() = %q, want %q", sig, tt.wantSig)
+			}
+			if fallback.called != tt.wantFallback {
+				t.Errorf("fallback called = %v, want %v", fallback.called, tt.wantFallback)
+			}
+			if got := annotations[notary.AnnotationSigningMethod] == notary.SigningMethodFallback; got != tt.wantAnnotation {
+				t.Errorf("SignWithAnnotations() annotations = %v", annotations)
+			}
+		})
+	}
+}
+
+func TestFallbackSignerVerify(t *testing.T) {
+	ctx := context.Background()
+	primary := newTestSigningService(t, "primary")
+	subject := oci.Descriptor{
+		MediaType: oci.MediaTypeImageManifest,
+		Digest:    digest.FromString("app"),
+		Size:      3,
+	}
+	primarySig, err := primary.Sign(ctx, subject)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	fallbackSig, err := newTestSigningService(t, "fallback").Sign(ctx, subject)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	other := subject
+	other.Digest = digest.FromString("other")
+
+	for _, tt := range []struct {
+		name         string
+		subject      oci.Descriptor
+		sig          []byte
+		wantFallback bool
+		wantErr      bool
+	}{
+		{name: "primary key", subject: subject, sig: primarySig},
+		{name: "unknown key", subject: subject, sig: fallbackSig, wantFallback: true},
+		{name: "content mismatch", subject: other, sig: primarySig, wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fallback := &fakeSigningService{}
+			signer := &notary.FallbackSigner{Primary: primary, Fallback: fallback}
+			_, err := signer.Verify(ctx, tt.subject, tt.sig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if fallback.verified != tt.wantFallback {
+				t.Errorf("fallback verified = %v, want %v", fallback.verified, tt.wantFallback)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/registry"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// MediaTypeChartContent specifies the media type for the Helm chart content layer.
+const MediaTypeChartContent = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+
+// ChartReference references a Helm chart stored in a registry
+type ChartReference struct {
+	Registry   string
+	Repository string
+	Version    string
+}
+
+// ParseChartReference parses a chart reference in the form of
+// registry/repository:version
+func ParseChartReference(reference string) (ChartReference, error) {
+	parts := strings.SplitN(reference, "/", 2)
+	if len(parts) != 2 {
+		return ChartReference{}, fmt.Errorf("invalid chart reference: %s: missing repository", reference)
+	}
+	i := strings.LastIndex(parts[1], ":")
+	if i < 0 {
+		return ChartReference{}, fmt.Errorf("invalid chart reference: %s: missing version", reference)
+	}
+	ref := ChartReference{
+		Registry:   parts[0],
+		Repository: parts[1][:i],
+		Version:    parts[1][i+1:],
+	}
+	if ref.Repository == "" || ref.Version == "" {
+		return ChartReference{}, fmt.Errorf("invalid chart reference: %s", reference)
+	}
+	return ref, nil
+}
+
+// Name returns the chart name
+func (r ChartReference) Name() string {
+	return path.Base(r.Repository)
+}
+
+func (r ChartReference) String() string {
+	return fmt.Sprintf("%s/%s:%s", r.Registry, r.Repository, r.Version)
+}
+
+// ChartSigner signs Helm charts stored in registries
+type ChartSigner struct {
+	Service   notary.SigningService
+	Transport http.RoundTripper
+	PlainHTTP bool
+}
+
+// Sign resolves the chart reference to its manifest, signs the manifest with
+// the reference, and links the signature to the manifest. The manifest must
+// have a chart content layer.
+func (s *ChartSigner) Sign(ctx context.Context, reference string) (oci.Descriptor, error) {
+	ref, err := ParseChartReference(reference)
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+	repo := registry.NewRepository(s.Transport, ref.Registry, ref.Repository, s.PlainHTTP)
+	manifest, err := repo.Resolve(ctx, ref.Version)
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+	if err := checkChart(ctx, repo, manifest); err != nil {
+		return oci.Descriptor{}, fmt.Errorf("%s: %w", ref, err)
+	}
+
+	sig, err := s.Service.Sign(ctx, manifest, notary.WithReferences(ref.String()))
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+	signature, err := repo.Put(ctx, sig)
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+	return repo.Link(ctx, manifest, signature)
+}
+
+// ChartVerifier verifies Helm charts stored in registries
+type ChartVerifier struct {
+	Service   notary.SigningService
+	Transport http.RoundTripper
+	PlainHTTP bool
+}
+
+// Verify resolves the chart reference to its manifest, and verifies that the
+// manifest is signed for the requested chart name and version. A signature
+// for another chart or version of the same manifest is rejected, which
+// prevents charts from being swapped.
+func (v *ChartVerifier) Verify(ctx context.Context, reference string) (oci.Descriptor, error) {
+	ref, err := ParseChartReference(reference)
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+	repo := registry.NewRepository(v.Transport, ref.Registry, ref.Repository, v.PlainHTTP)
+	manifest, err := repo.Resolve(ctx, ref.Version)
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+
+	switch code, _, err := notary.VerifyWithExitCode(ctx, repo, v.Service, manifest, ref.String()); code {
+	case notary.ExitValid:
+		return manifest, nil
+	case notary.ExitInvalid, notary.ExitPolicyViolation:
+		return oci.Descriptor{}, fmt.Errorf("no valid signature found for chart %s version %s: %w", ref.Name(), ref.Version, err)
+	default:
+		return oci.Descriptor{}, err
+	}
+}
+
+// checkChart checks that the manifest has a chart content layer
+func checkChart(ctx context.Context, repo *registry.Repository, manifest oci.Descriptor) error {
+	content, _, err := repo.GetManifest(ctx, manifest.Digest)
+	if err != nil {
+		return err
+	}
+	var chart oci.Manifest
+	if err := json.Unmarshal(content, &chart); err != nil {
+		return err
+	}
+	for _, layer := range chart.Layers {
+		if layer.MediaType == MediaTypeChartContent {
+			return nil
+		}
+	}
+	return errors.New("not a Helm chart: missing chart content layer")
+}
@@ -0,0 +1,178 @@
+package helm_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/libtrust"
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/helm"
+	"github.com/notaryproject/notary/v2/registry"
+	"github.com/notaryproject/notary/v2/registry/registrytest"
+	"github.com/notaryproject/notary/v2/simple"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// newTestSigningService creates a signing service with a P-256 key and a
+// self-signed certificate for the fake registry on the loopback address
+func newTestSigningService(t *testing.T) notary.SigningService {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "signer"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingKey, err := libtrust.FromCryptoPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service, err := simple.NewSigningService(signingKey, []*x509.Certificate{cert}, []*x509.Certificate{cert}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service
+}
+
+// pushTestManifest pushes a manifest with a layer of the given media type
+// under the tags
+func pushTestManifest(t *testing.T, server *registrytest.Server, repository, layerMediaType string, tags ...string) {
+	t.Helper()
+	manifest, err := json.Marshal(oci.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		Config: oci.Descriptor{
+			MediaType: "application/vnd.cncf.helm.config.v1+json",
+			Digest:    digest.FromString("config"),
+			Size:      6,
+		},
+		Layers: []oci.Descriptor{{
+			MediaType: layerMediaType,
+			Digest:    digest.FromString("chart"),
+			Size:      5,
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := registry.NewRepository(http.DefaultTransport, server.Host(), repository, true)
+	for _, tag := range tags {
+		if _, err := repo.PutTaggedManifest(context.Background(), manifest, oci.MediaTypeImageManifest, tag); err != nil {
+			t.Fatalf("PutTaggedManifest() error = %v", err)
+		}
+	}
+}
+
+func TestParseChartReference(t *testing.T) {
+	for _, tt := range []struct {
+		reference string
+		want      helm.ChartReference
+		wantErr   bool
+	}{
+		{
+			reference: "registry.example.com/charts/nginx:1.0.0",
+			want:      helm.ChartReference{Registry: "registry.example.com", Repository: "charts/nginx", Version: "1.0.0"},
+		},
+		{reference: "registry.example.com", wantErr: true},
+		{reference: "registry.example.com/charts/nginx", wantErr: true},
+		{reference: "registry.example.com/charts/nginx:", wantErr: true},
+	} {
+		t.Run(tt.reference, func(t *testing.T) {
+			got, err := helm.ParseChartReference(tt.reference)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseChartReference() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseChartReference() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChartSignVerify(t *testing.T) {
+	ctx := context.Background()
+	server := registrytest.NewServer()
+	defer server.Close()
+	pushTestManifest(t, server, "charts/nginx", helm.MediaTypeChartContent, "1.0.0", "2.0.0")
+	pushTestManifest(t, server, "charts/evil", helm.MediaTypeChartContent, "1.0.0")
+
+	service := newTestSigningService(t)
+	signer := &helm.ChartSigner{Service: service, Transport: http.DefaultTransport, PlainHTTP: true}
+	verifier := &helm.ChartVerifier{Service: service, Transport: http.DefaultTransport, PlainHTTP: true}
+	artifact, err := signer.Sign(ctx, server.Host()+"/charts/nginx:1.0.0")
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if _, err := verifier.Verify(ctx, server.Host()+"/charts/nginx:1.0.0"); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	// the same manifest tagged as another version is not signed for it
+	if _, err := verifier.Verify(ctx, server.Host()+"/charts/nginx:2.0.0"); err == nil || !strings.Contains(err.Error(), "no valid signature") {
+		t.Errorf("Verify() of another version error = %v, want no valid signature", err)
+	}
+
+	// the signature copied to another chart of the same content is rejected
+	nginx := registry.NewRepository(http.DefaultTransport, server.Host(), "charts/nginx", true)
+	evil := registry.NewRepository(http.DefaultTransport, server.Host(), "charts/evil", true)
+	linked, err := nginx.GetArtifactManifest(ctx, artifact.Digest)
+	if err != nil {
+		t.Fatalf("GetArtifactManifest() error = %v", err)
+	}
+	sig, err := nginx.Get(ctx, linked.Blobs[0].Digest)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	sigDesc, err := evil.Put(ctx, sig)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	manifest, err := evil.Resolve(ctx, "1.0.0")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if _, err := evil.Link(ctx, manifest, sigDesc); err != nil {
+		t.Fatalf("Link() error = %v", err)
+	}
+	if _, err := verifier.Verify(ctx, server.Host()+"/charts/evil:1.0.0"); err == nil || !strings.Contains(err.Error(), "no valid signature") {
+		t.Errorf("Verify() of another chart error = %v, want no valid signature", err)
+	}
+}
+
+func TestChartSignNotAChart(t *testing.T) {
+	server := registrytest.NewServer()
+	defer server.Close()
+	pushTestManifest(t, server, "images/app", oci.MediaTypeImageLayerGzip, "1.0.0")
+
+	signer := &helm.ChartSigner{Service: newTestSigningService(t), Transport: http.DefaultTransport, PlainHTTP: true}
+	if _, err := signer.Sign(context.Background(), server.Host()+"/images/app:1.0.0"); err == nil {
+		t.Error("Sign() of an image succeeded")
+	}
+}
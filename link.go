@@ -0,0 +1,90 @@
+package notary
+
+import (
+	"encoding/base64"
+
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// AnnotationTimestampSignature is the artifact annotation carrying the
+// RFC 3161 timestamp token of the signature, encoded as base64 DER.
+const AnnotationTimestampSignature = "io.cncf.notary.timestampSignature"
+
+// Artifact annotations carrying the platform of the signed manifest
+const (
+	AnnotationPlatformOS           = "io.cncf.notary.platform.os"
+	AnnotationPlatformArchitecture = "io.cncf.notary.platform.architecture"
+	AnnotationPlatformVariant      = "io.cncf.notary.platform.variant"
+)
+
+// LinkOption configures the artifact linking a signature to a manifest.
+type LinkOption func(*LinkOptions)
+
+// LinkOptions contains the optional parameters for linking.
+type LinkOptions struct {
+	// Annotations are added to the artifact manifest.
+	Annotations map[string]string
+
+	// ImageManifestCompat links the signature with an OCI image manifest
+	// instead of an artifact manifest.
+	ImageManifestCompat bool
+}
+
+// NewLinkOptions applies the link options.
+func NewLinkOptions(opts ...LinkOption) LinkOptions {
+	var options LinkOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// WithTimestamp stores the DER encoded RFC 3161 timestamp token of the
+// signature in the artifact manifest.
+func WithTimestamp(tst []byte) LinkOption {
+	return func(opts *LinkOptions) {
+		if opts.Annotations == nil {
+			opts.Annotations = make(map[string]string)
+		}
+		opts.Annotations[AnnotationTimestampSignature] = base64.StdEncoding.EncodeToString(tst)
+	}
+}
+
+// WithImageManifestCompat links the signature with an OCI image manifest
+// carrying the signature as its only layer, for registries which do not
+// support artifact manifests. Image manifests are only discoverable through
+// the referrers API, so linking fails on registries without it.
+func WithImageManifestCompat() LinkOption {
+	return func(opts *LinkOptions) {
+		opts.ImageManifestCompat = true
+	}
+}
+
+// WithPlatform records the platform of the signed manifest in the artifact
+// manifest, e.g. when signing the manifests of a multi-platform image.
+func WithPlatform(platform oci.Platform) LinkOption {
+	return func(opts *LinkOptions) {
+		if opts.Annotations == nil {
+			opts.Annotations = make(map[string]string)
+		}
+		opts.Annotations[AnnotationPlatformOS] = platform.OS
+		opts.Annotations[AnnotationPlatformArchitecture] = platform.Architecture
+		if platform.Variant != "" {
+			opts.Annotations[AnnotationPlatformVariant] = platform.Variant
+		}
+	}
+}
+
+// PlatformFromAnnotations returns the platform recorded by WithPlatform in the
+// artifact annotations, or nil if there is none.
+func PlatformFromAnnotations(annotations map[string]string) *oci.Platform {
+	os, arch := annotations[AnnotationPlatformOS], annotations[AnnotationPlatformArchitecture]
+	if os == "" || arch == "" {
+		return nil
+	}
+	return &oci.Platform{
+		OS:           os,
+		Architecture: arch,
+		Variant:      annotations[AnnotationPlatformVariant],
+	}
+}
@@ -0,0 +1,44 @@
+package notary
+
+// LookupOption configures the lookup of signatures.
+type LookupOption func(*LookupOptions)
+
+// LookupOptions contains the optional parameters for looking up signatures.
+type LookupOptions struct {
+	// FilterAnnotations are the annotations the artifact manifest linking a
+	// signature must have, with the same values.
+	FilterAnnotations map[string]string
+}
+
+// NewLookupOptions applies the lookup options.
+func NewLookupOptions(opts ...LookupOption) LookupOptions {
+	var options LookupOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// WithFilterAnnotations only looks up the signatures whose artifact manifest
+// has all the given annotations with the given values.
+func WithFilterAnnotations(annotations map[string]string) LookupOption {
+	return func(opts *LookupOptions) {
+		if opts.FilterAnnotations == nil {
+			opts.FilterAnnotations = make(map[string]string)
+		}
+		for key, value := range annotations {
+			opts.FilterAnnotations[key] = value
+		}
+	}
+}
+
+// MatchAnnotations reports whether the annotations contain all the filter
+// annotations with the same values.
+func (o LookupOptions) MatchAnnotations(annotations map[string]string) bool {
+	for key, value := range o.FilterAnnotations {
+		if actual, found := annotations[key]; !found || actual != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,153 @@
+package notary
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/notaryproject/notary/v2/signature"
+	x509nv2 "github.com/notaryproject/notary/v2/signature/x509"
+	artifactspec "github.com/opencontainers/artifacts/specs-go/v2"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// SignatureManifest gathers the artifact manifest linking a signature, the
+// signature blob and its unverified content, so that they can be inspected
+// together.
+type SignatureManifest struct {
+	SignatureRef
+
+	// Signature is the signature blob
+	Signature []byte
+
+	// Claims are the claims of the signature, which are NOT verified
+	Claims signature.Claims
+
+	// CertChain is the certificate chain in the x5c header of the signature,
+	// leaf first, which is NOT verified. It is empty for signatures not signed
+	// with an x5c header.
+	CertChain []*x509.Certificate
+
+	// Annotations are the annotations of the artifact manifest
+	Annotations map[string]string
+}
+
+// artifactManifestGetter is implemented by signature repositories which can
+// fetch artifact manifests by their own digest
+type artifactManifestGetter interface {
+	GetArtifactManifest(ctx context.Context, artifactDigest digest.Digest) (artifactspec.Artifact, error)
+}
+
+// FetchSignatureManifest fetches the artifact manifest described by
+// artifactDesc and the signature it links, e.g. the descriptor returned by
+// Link. The repository must be able to fetch artifact manifests by digest.
+func FetchSignatureManifest(ctx context.Context, artifactDesc oci.Descriptor, repo SignatureRepository) (SignatureManifest, error) {
+	getter, ok := repo.(artifactManifestGetter)
+	if !ok {
+		return SignatureManifest{}, errors.New("fetch signature manifest: the repository does not support fetching artifact manifests")
+	}
+	artifact, err := getter.GetArtifactManifest(ctx, artifactDesc.Digest)
+	if err != nil {
+		return SignatureManifest{}, err
+	}
+	if len(artifact.Blobs) != 1 {
+		return SignatureManifest{}, &NotaryError{
+			Code:  ErrCodeInvalidFormat,
+			Op:    "fetch signature manifest",
+			Cause: fmt.Errorf("artifact links %d signatures, want 1", len(artifact.Blobs)),
+		}
+	}
+
+	ref := SignatureRef{
+		ArtifactDescriptor:      artifactDesc,
+		SignatureBlobDescriptor: ociDescriptorFromArtifact(artifact.Blobs[0]),
+		SubjectDescriptor:       ociDescriptorFromArtifact(artifact.SubjectManifest),
+		Platform:                PlatformFromAnnotations(artifact.Annotations),
+	}
+	sig, err := ref.Fetch(ctx, repo)
+	if err != nil {
+		return SignatureManifest{}, err
+	}
+	claims, err := signature.UnverifiedClaims(sig)
+	if err != nil {
+		return SignatureManifest{}, &NotaryError{
+			Code:  ErrCodeInvalidFormat,
+			Op:    "fetch signature manifest",
+			Cause: err,
+		}
+	}
+	certs, err := x509nv2.CertificateChain(sig)
+	if err != nil && !errors.Is(err, signature.ErrInvalidSignatureType) {
+		return SignatureManifest{}, &NotaryError{
+			Code:  ErrCodeInvalidFormat,
+			Op:    "fetch signature manifest",
+			Cause: err,
+		}
+	}
+	return SignatureManifest{
+		SignatureRef: ref,
+		Signature:    sig,
+		Claims:       claims,
+		CertChain:    certs,
+		Annotations:  artifact.Annotations,
+	}, nil
+}
+
+// Verify verifies the signature against the signed manifest.
+// If verifier is nil, the verifier carried by ctx is used. Without either, a
+// signature whose artifact embeds its certificate chain in the cosign
+// certificate annotation is verified with that chain, which must be trusted by
+// the trust store carried by ctx or the system roots.
+// On success, the signed references are returned.
+func (m SignatureManifest) Verify(ctx context.Context, verifier Verifier) ([]string, error) {
+	if verifier == nil {
+		var ok bool
+		if verifier, ok = VerifierFromContext(ctx); !ok {
+			if _, inline := m.Annotations[x509nv2.AnnotationCosignCertificate]; inline {
+				return m.verifyInline(ctx)
+			}
+			return nil, errors.New("no verifier provided")
+		}
+	}
+	return verifier.Verify(ctx, m.SubjectDescriptor, m.Signature)
+}
+
+// verifyInline verifies the signature with the certificate chain embedded in
+// the annotations.
+func (m SignatureManifest) verifyInline(ctx context.Context) ([]string, error) {
+	roots, _ := TrustStoreFromContext(ctx)
+	verifier, err := x509nv2.NewInlineVerifier(m.Annotations, roots)
+	if err != nil {
+		return nil, err
+	}
+	scheme := signature.NewScheme()
+	scheme.RegisterVerifier(verifier)
+	claims, err := scheme.Verify(string(m.Signature))
+	if err != nil {
+		return nil, err
+	}
+	subject := signature.Descriptor{
+		MediaType: m.SubjectDescriptor.MediaType,
+		Digest:    m.SubjectDescriptor.Digest.String(),
+		Size:      m.SubjectDescriptor.Size,
+	}
+	if claims.Manifest.Descriptor != subject {
+		return nil, &NotaryError{
+			Code:  ErrCodeDigestMismatch,
+			Op:    "verify",
+			Cause: fmt.Errorf("signature subject %v does not match %v", claims.Manifest.Descriptor, subject),
+		}
+	}
+	return claims.Manifest.References, nil
+}
+
+func ociDescriptorFromArtifact(desc artifactspec.Descriptor) oci.Descriptor {
+	return oci.Descriptor{
+		MediaType:   desc.MediaType,
+		Digest:      desc.Digest,
+		Size:        desc.Size,
+		Annotations: desc.Annotations,
+	}
+}
@@ -0,0 +1,123 @@
+package notary_test
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/registry"
+	"github.com/notaryproject/notary/v2/registry/registrytest"
+	x509nv2 "github.com/notaryproject/notary/v2/signature/x509"
+	"github.com/notaryproject/notary/v2/simple"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestFetchSignatureManifest(t *testing.T) {
+	ctx := context.Background()
+	server := registrytest.NewServer()
+	defer server.Close()
+	repo := registry.NewRepository(http.DefaultTransport, server.Host(), "test/app", true)
+	service := newTestSigningService(t, "signer")
+	subject := oci.Descriptor{
+		MediaType: oci.MediaTypeImageManifest,
+		Digest:    digest.FromString("app"),
+		Size:      3,
+	}
+	sig, err := service.Sign(ctx, subject)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	sigDesc, err := repo.Put(ctx, sig)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	platform := oci.Platform{OS: "linux", Architecture: "arm64"}
+	artifactDesc, err := repo.Link(ctx, subject, sigDesc, notary.WithPlatform(platform))
+	if err != nil {
+		t.Fatalf("Link() error = %v", err)
+	}
+
+	manifest, err := notary.FetchSignatureManifest(ctx, artifactDesc, repo)
+	if err != nil {
+		t.Fatalf("FetchSignatureManifest() error = %v", err)
+	}
+	if manifest.SignatureBlobDescriptor.Digest != sigDesc.Digest {
+		t.Errorf("signature = %v, want %v", manifest.SignatureBlobDescriptor.Digest, sigDesc.Digest)
+	}
+	if manifest.SubjectDescriptor.Digest != subject.Digest {
+		t.Errorf("subject = %v, want %v", manifest.SubjectDescriptor.Digest, subject.Digest)
+	}
+	if manifest.Claims.Manifest.Digest != subject.Digest.String() {
+		t.Errorf("claims digest = %v, want %v", manifest.Claims.Manifest.Digest, subject.Digest)
+	}
+	if len(manifest.CertChain) != 1 || manifest.CertChain[0].Subject.CommonName != "signer" {
+		t.Errorf("CertChain = %v, want the signer certificate", manifest.CertChain)
+	}
+	if got := manifest.Annotations[notary.AnnotationPlatformArchitecture]; got != "arm64" {
+		t.Errorf("annotation %s = %q, want %q", notary.AnnotationPlatformArchitecture, got, "arm64")
+	}
+	if _, err := manifest.Verify(ctx, service); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if _, err := manifest.Verify(ctx, newTestSigningService(t, "other")); err == nil {
+		t.Error("Verify() with another signer succeeded")
+	}
+}
+
+func TestFetchSignatureManifestUnsupportedRepository(t *testing.T) {
+	server := registrytest.NewServer()
+	defer server.Close()
+	// hide the methods of the repository beyond the interface
+	repo := struct{ notary.SignatureRepository }{
+		registry.NewRepository(http.DefaultTransport, server.Host(), "test/app", true),
+	}
+	if _, err := notary.FetchSignatureManifest(context.Background(), oci.Descriptor{}, repo); err == nil {
+		t.Fatal("FetchSignatureManifest() with an unsupported repository succeeded")
+	}
+}
+
+func TestSignatureManifestVerifyInlineCertificate(t *testing.T) {
+	ctx := context.Background()
+	key, cert := newTestKeyPair(t, "signer")
+	service, err := simple.NewSigningService(key, []*x509.Certificate{cert}, []*x509.Certificate{cert}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject := oci.Descriptor{
+		MediaType: oci.MediaTypeImageManifest,
+		Digest:    digest.FromString("app"),
+		Size:      3,
+	}
+	sig, err := service.Sign(ctx, subject)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	manifest := notary.SignatureManifest{
+		SignatureRef: notary.SignatureRef{
+			SubjectDescriptor: subject,
+		},
+		Signature: sig,
+		Annotations: map[string]string{
+			x509nv2.AnnotationCosignCertificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})),
+		},
+	}
+
+	if _, err := manifest.Verify(ctx, nil); err == nil {
+		t.Error("Verify() with an untrusted inline certificate succeeded")
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	trusted := notary.WithTrustStore(ctx, roots)
+	if _, err := manifest.Verify(trusted, nil); err != nil {
+		t.Errorf("Verify() with the inline certificate error = %v", err)
+	}
+	manifest.SubjectDescriptor.Digest = digest.FromString("other")
+	if _, err := manifest.Verify(trusted, nil); !errors.Is(err, notary.ErrDigestMismatch) {
+		t.Errorf("Verify() of another subject error = %v, want ErrDigestMismatch", err)
+	}
+}
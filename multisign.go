@@ -0,0 +1,36 @@
+package notary
+
+import (
+	"context"
+	"sync"
+
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// MultiKeySigner signs with multiple signing services in parallel
+type MultiKeySigner struct {
+	Signers []SigningService
+}
+
+// Sign signs the descriptor with all signers in parallel, and returns the
+// signatures in the order of the signers.
+func (s MultiKeySigner) Sign(ctx context.Context, desc oci.Descriptor, opts ...SignOption) ([][]byte, error) {
+	sigs := make([][]byte, len(s.Signers))
+	errs := make([]error, len(s.Signers))
+	var wg sync.WaitGroup
+	for i, signer := range s.Signers {
+		wg.Add(1)
+		go func(i int, signer SigningService) {
+			defer wg.Done()
+			sigs[i], errs[i] = signer.Sign(ctx, desc, opts...)
+		}(i, signer)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sigs, nil
+}
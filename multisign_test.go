@@ -0,0 +1,84 @@
+package notary_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/registry"
+	"github.com/notaryproject/notary/v2/registry/registrytest"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestMultiKeySigner(t *testing.T) {
+	ctx := context.Background()
+	server := registrytest.NewServer()
+	defer server.Close()
+	repo := registry.NewRepository(http.DefaultTransport, server.Host(), "test/app", true)
+	services := []notary.SigningService{
+		newTestSigningService(t, "deployment"),
+		newTestSigningService(t, "customer"),
+		newTestSigningService(t, "log"),
+	}
+	subject := oci.Descriptor{
+		MediaType: oci.MediaTypeImageManifest,
+		Digest:    digest.FromString("app"),
+		Size:      3,
+	}
+
+	sigs, err := notary.MultiKeySigner{Signers: services}.Sign(ctx, subject)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if len(sigs) != len(services) {
+		t.Fatalf("Sign() = %d signatures, want %d", len(sigs), len(services))
+	}
+	for i, sig := range sigs {
+		for j, other := range sigs[:i] {
+			if bytes.Equal(sig, other) {
+				t.Errorf("signatures %d and %d are identical", j, i)
+			}
+		}
+		// each signature verifies with its own signer only
+		for j, service := range services {
+			_, err := service.Verify(ctx, subject, sig)
+			if (err == nil) != (i == j) {
+				t.Errorf("signature %d: Verify() by signer %d error = %v", i, j, err)
+			}
+		}
+	}
+
+	descs, err := repo.PutAll(ctx, sigs)
+	if err != nil {
+		t.Fatalf("PutAll() error = %v", err)
+	}
+	if len(descs) != len(sigs) {
+		t.Fatalf("PutAll() = %d descriptors, want %d", len(descs), len(sigs))
+	}
+	for i, desc := range descs {
+		if desc.Digest != digest.FromBytes(sigs[i]) {
+			t.Errorf("PutAll() descriptor %d = %v, want %v", i, desc.Digest, digest.FromBytes(sigs[i]))
+		}
+		got, err := repo.Get(ctx, desc.Digest)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if !bytes.Equal(got, sigs[i]) {
+			t.Errorf("Get() = %q, want signature %d", got, i)
+		}
+	}
+}
+
+func TestMultiKeySignerError(t *testing.T) {
+	signer := notary.MultiKeySigner{Signers: []notary.SigningService{
+		newTestSigningService(t, "signer"),
+		&fakeSigningService{err: errors.New("key not found")},
+	}}
+	if _, err := signer.Sign(context.Background(), oci.Descriptor{}); err == nil {
+		t.Fatal("Sign() with a failing signer succeeded")
+	}
+}
@@ -0,0 +1,107 @@
+package notary
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// QuotaExceededError is returned when the signing quota of the window is used
+// up.
+type QuotaExceededError struct {
+	NotaryError
+	Quota  int
+	Window time.Duration
+	Used   int
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("signing quota exceeded: %d of %d signatures used within %v", e.Used, e.Quota, e.Window)
+}
+
+// QuotaSigner limits the signatures produced by the inner signing service to
+// Quota per Window, e.g. to protect the transaction limits of an HSM shared by
+// multiple tenants. The quota is shared by all goroutines signing with the same
+// QuotaSigner, and is reset once the window rolls over.
+// Failed signing attempts do not count against the quota.
+type QuotaSigner struct {
+	Inner  SigningService
+	Quota  int
+	Window time.Duration
+
+	mu    sync.Mutex
+	used  int
+	start time.Time
+}
+
+// Sign signs with the inner signing service if the quota is not used up.
+func (s *QuotaSigner) Sign(ctx context.Context, desc oci.Descriptor, opts ...SignOption) ([]byte, error) {
+	window, err := s.acquire()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := s.Inner.Sign(ctx, desc, opts...)
+	if err != nil {
+		s.release(window)
+		return nil, err
+	}
+	return sig, nil
+}
+
+// Verify verifies with the inner signing service. Verification is not limited
+// by the quota.
+func (s *QuotaSigner) Verify(ctx context.Context, desc oci.Descriptor, signature []byte) ([]string, error) {
+	return s.Inner.Verify(ctx, desc, signature)
+}
+
+// Used returns the number of signatures counted in the current window.
+func (s *QuotaSigner) Used() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.roll()
+	return s.used
+}
+
+// acquire reserves a signature from the quota before signing, so that
+// concurrent callers cannot overshoot it. The start of the window is returned
+// for releasing the reservation.
+func (s *QuotaSigner) acquire() (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.roll()
+	if s.used >= s.Quota {
+		return time.Time{}, &QuotaExceededError{
+			NotaryError: NotaryError{
+				Code: ErrCodeQuotaExceeded,
+				Op:   "sign",
+			},
+			Quota:  s.Quota,
+			Window: s.Window,
+			Used:   s.used,
+		}
+	}
+	s.used++
+	return s.start, nil
+}
+
+// release returns a reserved signature to the quota, unless the window it was
+// reserved in is already over.
+func (s *QuotaSigner) release(window time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.start.Equal(window) && s.used > 0 {
+		s.used--
+	}
+}
+
+// roll starts a new window if the current one is over.
+func (s *QuotaSigner) roll() {
+	now := time.Now()
+	if s.start.IsZero() || now.Sub(s.start) >= s.Window {
+		s.start = now
+		s.used = 0
+	}
+}
@@ -0,0 +1,91 @@
+package notary_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notary/v2"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestQuotaSigner(t *testing.T) {
+	ctx := context.Background()
+	inner := &fakeSigningService{sig: []byte("signature")}
+	signer := &notary.QuotaSigner{
+		Inner:  inner,
+		Quota:  2,
+		Window: time.Hour,
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := signer.Sign(ctx, oci.Descriptor{}); err != nil {
+			t.Fatalf("Sign() error = %v", err)
+		}
+	}
+
+	inner.called = false
+	_, err := signer.Sign(ctx, oci.Descriptor{})
+	var quotaErr *notary.QuotaExceededError
+	if !errors.As(err, &quotaErr) {
+		t.Fatalf("Sign() error = %v, want QuotaExceededError", err)
+	}
+	if quotaErr.Quota != 2 || quotaErr.Used != 2 || quotaErr.Window != time.Hour {
+		t.Errorf("Sign() error = %+v, want 2 of 2 used within 1h", quotaErr)
+	}
+	if !errors.Is(err, notary.ErrQuotaExceeded) {
+		t.Errorf("Sign() error = %v, want %v", err, notary.ErrQuotaExceeded)
+	}
+	if inner.called {
+		t.Error("Sign() called the inner signing service beyond the quota")
+	}
+
+	// verification is not limited by the quota
+	if _, err := signer.Verify(ctx, oci.Descriptor{}, []byte("signature")); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+}
+
+func TestQuotaSignerFailedAttempt(t *testing.T) {
+	ctx := context.Background()
+	inner := &fakeSigningService{err: notary.ErrSignerUnavailable}
+	signer := &notary.QuotaSigner{
+		Inner:  inner,
+		Quota:  1,
+		Window: time.Hour,
+	}
+	if _, err := signer.Sign(ctx, oci.Descriptor{}); !errors.Is(err, notary.ErrSignerUnavailable) {
+		t.Fatalf("Sign() error = %v, want %v", err, notary.ErrSignerUnavailable)
+	}
+	if used := signer.Used(); used != 0 {
+		t.Errorf("Used() = %d, want the failed attempt not counted", used)
+	}
+
+	inner.err = nil
+	inner.sig = []byte("signature")
+	if _, err := signer.Sign(ctx, oci.Descriptor{}); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if used := signer.Used(); used != 1 {
+		t.Errorf("Used() = %d, want 1", used)
+	}
+}
+
+func TestQuotaSignerWindow(t *testing.T) {
+	ctx := context.Background()
+	signer := &notary.QuotaSigner{
+		Inner:  &fakeSigningService{sig: []byte("signature")},
+		Quota:  1,
+		Window: 50 * time.Millisecond,
+	}
+	if _, err := signer.Sign(ctx, oci.Descriptor{}); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if _, err := signer.Sign(ctx, oci.Descriptor{}); !errors.Is(err, notary.ErrQuotaExceeded) {
+		t.Fatalf("Sign() error = %v, want %v", err, notary.ErrQuotaExceeded)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if _, err := signer.Sign(ctx, oci.Descriptor{}); err != nil {
+		t.Errorf("Sign() in the next window error = %v", err)
+	}
+}
@@ -15,7 +15,10 @@ type SignatureRegistry interface {
 // SignatureRepository provides a storage for signatures
 type SignatureRepository interface {
 	// Lookup finds all signatures for the specified manifest
-	Lookup(ctx context.Context, manifestDigest digest.Digest) ([]digest.Digest, error)
+	Lookup(ctx context.Context, manifestDigest digest.Digest, opts ...LookupOption) ([]SignatureRef, error)
+
+	// LookupSet finds all signatures for the specified manifest as a set
+	LookupSet(ctx context.Context, manifestDigest digest.Digest, opts ...LookupOption) (*SignatureSet, error)
 
 	// Get downloads the signature by the specified digest
 	Get(ctx context.Context, signatureDigest digest.Digest) ([]byte, error)
@@ -23,6 +26,39 @@ type SignatureRepository interface {
 	// Put uploads the signature to the registry
 	Put(ctx context.Context, signature []byte) (oci.Descriptor, error)
 
-	// Link creates an signature artifact linking the manifest and the signature
-	Link(ctx context.Context, manifest, signature oci.Descriptor) (oci.Descriptor, error)
+	// PutAll uploads the signatures to the registry concurrently
+	PutAll(ctx context.Context, signatures [][]byte) ([]oci.Descriptor, error)
+
+	// Link creates an signature artifact linking the manifest and the signature.
+	// The annotations of the signature descriptor are kept in the artifact.
+	Link(ctx context.Context, manifest, signature oci.Descriptor, opts ...LinkOption) (oci.Descriptor, error)
+}
+
+// NamedRepository is a signature repository knowing its name prefixed by the
+// registry host, e.g. registry.example.com/teamA/app
+type NamedRepository interface {
+	SignatureRepository
+
+	// FullName returns the name of the repository prefixed by the registry host
+	FullName() string
+}
+
+// SignatureRef references a signature blob and the artifact linking it to a manifest
+type SignatureRef struct {
+	// ArtifactDescriptor describes the artifact manifest linking the signature
+	ArtifactDescriptor oci.Descriptor
+
+	// SignatureBlobDescriptor describes the signature blob
+	SignatureBlobDescriptor oci.Descriptor
+
+	// SubjectDescriptor describes the signed manifest
+	SubjectDescriptor oci.Descriptor
+
+	// Platform is the platform of the signed manifest if recorded on linking
+	Platform *oci.Platform
+}
+
+// Fetch downloads the referenced signature from the repository
+func (r SignatureRef) Fetch(ctx context.Context, repo SignatureRepository) ([]byte, error) {
+	return repo.Get(ctx, r.SignatureBlobDescriptor.Digest)
 }
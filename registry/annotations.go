@@ -0,0 +1,75 @@
+package registry
+
+import (
+	"strings"
+	"time"
+
+	artifactspec "github.com/opencontainers/artifacts/specs-go/v2"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// annotationDescription is the OCI annotation for the description of the
+// content, which is missing in image-spec v1.0.1
+const annotationDescription = "org.opencontainers.image.description"
+
+// ArtifactAnnotations are the standard OCI annotations of an artifact
+type ArtifactAnnotations struct {
+	// CreatedAt is read from org.opencontainers.image.created
+	CreatedAt time.Time
+
+	// Description is read from org.opencontainers.image.description
+	Description string
+
+	// Authors are read from org.opencontainers.image.authors as a comma
+	// separated list
+	Authors []string
+
+	// BuildURL is read from org.opencontainers.image.url
+	BuildURL string
+
+	// GitRevision is read from org.opencontainers.image.revision
+	GitRevision string
+}
+
+// StandardAnnotations parses the standard OCI annotations of the artifact.
+// Annotations that are missing or malformed are left as zero values.
+func StandardAnnotations(a artifactspec.Artifact) ArtifactAnnotations {
+	annotations := ArtifactAnnotations{
+		Description: a.Annotations[annotationDescription],
+		BuildURL:    a.Annotations[oci.AnnotationURL],
+		GitRevision: a.Annotations[oci.AnnotationRevision],
+	}
+	if created, err := time.Parse(time.RFC3339, a.Annotations[oci.AnnotationCreated]); err == nil {
+		annotations.CreatedAt = created
+	}
+	if authors := a.Annotations[oci.AnnotationAuthors]; authors != "" {
+		for _, author := range strings.Split(authors, ",") {
+			if author = strings.TrimSpace(author); author != "" {
+				annotations.Authors = append(annotations.Authors, author)
+			}
+		}
+	}
+	return annotations
+}
+
+// ToMap encodes the annotations under their standard OCI keys, omitting the
+// zero values, e.g. to set the annotations of an artifact.
+func (a ArtifactAnnotations) ToMap() map[string]string {
+	annotations := make(map[string]string)
+	if !a.CreatedAt.IsZero() {
+		annotations[oci.AnnotationCreated] = a.CreatedAt.Format(time.RFC3339)
+	}
+	if a.Description != "" {
+		annotations[annotationDescription] = a.Description
+	}
+	if len(a.Authors) > 0 {
+		annotations[oci.AnnotationAuthors] = strings.Join(a.Authors, ", ")
+	}
+	if a.BuildURL != "" {
+		annotations[oci.AnnotationURL] = a.BuildURL
+	}
+	if a.GitRevision != "" {
+		annotations[oci.AnnotationRevision] = a.GitRevision
+	}
+	return annotations
+}
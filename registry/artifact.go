@@ -0,0 +1,245 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+
+	canonicaljson "github.com/docker/go/canonical/json"
+	"github.com/notaryproject/notary/v2"
+
+	artifactspec "github.com/opencontainers/artifacts/specs-go/v2"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// artifactFields lists the JSON fields allowed in an artifact manifest.
+var artifactFields = map[string]struct{}{
+	"schemaVersion":   {},
+	"mediaType":       {},
+	"artifactType":    {},
+	"blobs":           {},
+	"subjectManifest": {},
+	"annotations":     {},
+}
+
+// ociArtifact is the artifact manifest defined by the OCI distribution spec
+// v1.1, which refers to the signed manifest by the subject field.
+type ociArtifact struct {
+	MediaType    string            `json:"mediaType"`
+	ArtifactType string            `json:"artifactType"`
+	Blobs        []oci.Descriptor  `json:"blobs,omitempty"`
+	Subject      *oci.Descriptor   `json:"subject,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
+func newOCIArtifact(a artifactspec.Artifact) ociArtifact {
+	blobs := make([]oci.Descriptor, 0, len(a.Blobs))
+	for _, blob := range a.Blobs {
+		blobs = append(blobs, ociDescriptorFromArtifact(blob))
+	}
+	subject := ociDescriptorFromArtifact(a.SubjectManifest)
+	return ociArtifact{
+		MediaType:    MediaTypeOCIArtifactManifest,
+		ArtifactType: a.ArtifactType,
+		Blobs:        blobs,
+		Subject:      &subject,
+		Annotations:  a.Annotations,
+	}
+}
+
+func (a ociArtifact) artifact() artifactspec.Artifact {
+	blobs := make([]artifactspec.Descriptor, 0, len(a.Blobs))
+	for _, blob := range a.Blobs {
+		blobs = append(blobs, artifactDescriptorFromOCI(blob))
+	}
+	artifact := artifactspec.Artifact{
+		MediaType:    a.MediaType,
+		ArtifactType: a.ArtifactType,
+		Blobs:        blobs,
+		Annotations:  a.Annotations,
+	}
+	if a.Subject != nil {
+		artifact.SubjectManifest = artifactDescriptorFromOCI(*a.Subject)
+	}
+	return artifact
+}
+
+// imageArtifactConfig is the synthetic config of the image manifests
+// carrying artifacts
+var imageArtifactConfig = []byte("{}")
+
+// ociImageArtifact is an OCI image manifest carrying the blobs of an artifact
+// as its layers, for registries not supporting artifact manifests.
+type ociImageArtifact struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	Config        oci.Descriptor    `json:"config"`
+	Layers        []oci.Descriptor  `json:"layers"`
+	Subject       *oci.Descriptor   `json:"subject,omitempty"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// newOCIImageArtifact wraps the artifact in an image manifest, whose config
+// media type is the artifact type.
+func newOCIImageArtifact(a artifactspec.Artifact) ociImageArtifact {
+	layers := make([]oci.Descriptor, 0, len(a.Blobs))
+	for _, blob := range a.Blobs {
+		layer := ociDescriptorFromArtifact(blob)
+		layer.MediaType = MediaTypeNotarySignatureLayer
+		layers = append(layers, layer)
+	}
+	config := DescriptorFromBytes(imageArtifactConfig)
+	config.MediaType = a.ArtifactType
+	subject := ociDescriptorFromArtifact(a.SubjectManifest)
+	return ociImageArtifact{
+		SchemaVersion: 2,
+		MediaType:     oci.MediaTypeImageManifest,
+		Config:        config,
+		Layers:        layers,
+		Subject:       &subject,
+		Annotations:   a.Annotations,
+	}
+}
+
+func (m ociImageArtifact) artifact() artifactspec.Artifact {
+	blobs := make([]artifactspec.Descriptor, 0, len(m.Layers))
+	for _, layer := range m.Layers {
+		blobs = append(blobs, artifactDescriptorFromOCI(layer))
+	}
+	// the mediaType field is optional in image manifests, so the format is
+	// known from the config carrying the artifact type instead
+	artifact := artifactspec.Artifact{
+		MediaType:    oci.MediaTypeImageManifest,
+		ArtifactType: m.Config.MediaType,
+		Blobs:        blobs,
+		Annotations:  m.Annotations,
+	}
+	if m.Subject != nil {
+		artifact.SubjectManifest = artifactDescriptorFromOCI(*m.Subject)
+	}
+	return artifact
+}
+
+// parseOCIReferrer decodes a referrer found by the referrers API, which is
+// either an artifact manifest or an image manifest carrying an artifact.
+func parseOCIReferrer(data []byte, mediaType string) (artifactspec.Artifact, error) {
+	if mediaType == oci.MediaTypeImageManifest {
+		var manifest ociImageArtifact
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return artifactspec.Artifact{}, err
+		}
+		return manifest.artifact(), nil
+	}
+	var manifest ociArtifact
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return artifactspec.Artifact{}, err
+	}
+	return manifest.artifact(), nil
+}
+
+// MarshalArtifactJSON encodes the artifact manifest as canonical JSON, so
+// that identical artifacts always have identical digests.
+func MarshalArtifactJSON(a artifactspec.Artifact) ([]byte, error) {
+	return canonicaljson.MarshalCanonical(a)
+}
+
+// ValidateArtifact checks that the artifact manifest is well-formed before it
+// is marshaled and pushed.
+func ValidateArtifact(a artifactspec.Artifact) error {
+	if err := validateArtifact(a); err != nil {
+		return &notary.NotaryError{
+			Code:  notary.ErrCodeInvalidFormat,
+			Op:    "validate artifact",
+			Cause: err,
+		}
+	}
+	return nil
+}
+
+func validateArtifact(a artifactspec.Artifact) error {
+	if a.SchemaVersion != 3 {
+		return fmt.Errorf("unsupported schema version: %d", a.SchemaVersion)
+	}
+	if a.MediaType != artifactspec.MediaTypeArtifactManifest {
+		return fmt.Errorf("unsupported media type: %q", a.MediaType)
+	}
+	if !isMediaType(a.ArtifactType) {
+		return fmt.Errorf("invalid artifact type: %q", a.ArtifactType)
+	}
+	for i, blob := range a.Blobs {
+		if blob.Size <= 0 {
+			return fmt.Errorf("blob %d: invalid size: %d", i, blob.Size)
+		}
+		if err := blob.Digest.Validate(); err != nil {
+			return fmt.Errorf("blob %d: %w", i, err)
+		}
+	}
+	if err := a.SubjectManifest.Digest.Validate(); err != nil {
+		return fmt.Errorf("subject manifest: %w", err)
+	}
+	return nil
+}
+
+// isMediaType reports whether s is a media type of the form type/subtype as
+// defined by RFC 6838.
+func isMediaType(s string) bool {
+	mediaType, _, err := mime.ParseMediaType(s)
+	if err != nil {
+		return false
+	}
+	parts := strings.Split(mediaType, "/")
+	return len(parts) == 2 && parts[0] != "" && parts[1] != ""
+}
+
+// ParseArtifactManifest decodes the artifact manifest of either the artifacts
+// extension or the OCI distribution spec v1.1, or the image manifest carrying
+// an artifact, as indicated by mediaType.
+func ParseArtifactManifest(data []byte, mediaType string) (artifactspec.Artifact, error) {
+	switch mediaType {
+	case artifactspec.MediaTypeArtifactManifest:
+		var artifact artifactspec.Artifact
+		if err := UnmarshalArtifactJSON(data, &artifact); err != nil {
+			return artifactspec.Artifact{}, err
+		}
+		if artifact.MediaType != mediaType {
+			return artifactspec.Artifact{}, fmt.Errorf("unexpected artifact manifest media type: %q", artifact.MediaType)
+		}
+		return artifact, nil
+	case MediaTypeOCIArtifactManifest:
+		var manifest ociArtifact
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return artifactspec.Artifact{}, err
+		}
+		if manifest.MediaType != mediaType {
+			return artifactspec.Artifact{}, fmt.Errorf("unexpected artifact manifest media type: %q", manifest.MediaType)
+		}
+		return manifest.artifact(), nil
+	case oci.MediaTypeImageManifest:
+		var manifest ociImageArtifact
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return artifactspec.Artifact{}, err
+		}
+		if manifest.MediaType != "" && manifest.MediaType != mediaType {
+			return artifactspec.Artifact{}, fmt.Errorf("unexpected image manifest media type: %q", manifest.MediaType)
+		}
+		return manifest.artifact(), nil
+	default:
+		return artifactspec.Artifact{}, fmt.Errorf("unsupported artifact manifest media type: %q", mediaType)
+	}
+}
+
+// UnmarshalArtifactJSON decodes the artifact manifest from JSON, rejecting
+// any unknown fields.
+func UnmarshalArtifactJSON(data []byte, a *artifactspec.Artifact) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for key := range fields {
+		if _, ok := artifactFields[key]; !ok {
+			return fmt.Errorf("unknown artifact manifest field: %q", key)
+		}
+	}
+	return json.Unmarshal(data, a)
+}
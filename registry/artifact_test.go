@@ -0,0 +1,61 @@
+package registry_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/registry"
+	artifactspecs "github.com/opencontainers/artifacts/specs-go"
+	artifactspec "github.com/opencontainers/artifacts/specs-go/v2"
+	"github.com/opencontainers/go-digest"
+)
+
+func TestValidateArtifact(t *testing.T) {
+	valid := func() artifactspec.Artifact {
+		return artifactspec.Artifact{
+			Versioned: artifactspecs.Versioned{
+				SchemaVersion: 3,
+			},
+			MediaType:    artifactspec.MediaTypeArtifactManifest,
+			ArtifactType: registry.ArtifactTypeNotaryV2,
+			Blobs: []artifactspec.Descriptor{{
+				MediaType: registry.MediaTypeNotarySignatureLayer,
+				Digest:    digest.FromString("signature"),
+				Size:      9,
+			}},
+			SubjectManifest: artifactspec.Descriptor{
+				Digest: digest.FromString("subject"),
+				Size:   7,
+			},
+		}
+	}
+	for _, tt := range []struct {
+		name    string
+		modify  func(*artifactspec.Artifact)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(a *artifactspec.Artifact) {}},
+		{name: "artifact type with parameters", modify: func(a *artifactspec.Artifact) { a.ArtifactType = "application/vnd.example+json; version=1" }},
+		{name: "schema version", modify: func(a *artifactspec.Artifact) { a.SchemaVersion = 2 }, wantErr: true},
+		{name: "media type", modify: func(a *artifactspec.Artifact) { a.MediaType = "application/json" }, wantErr: true},
+		{name: "empty artifact type", modify: func(a *artifactspec.Artifact) { a.ArtifactType = "" }, wantErr: true},
+		{name: "artifact type without subtype", modify: func(a *artifactspec.Artifact) { a.ArtifactType = "application/" }, wantErr: true},
+		{name: "artifact type without slash", modify: func(a *artifactspec.Artifact) { a.ArtifactType = "signature" }, wantErr: true},
+		{name: "zero blob size", modify: func(a *artifactspec.Artifact) { a.Blobs[0].Size = 0 }, wantErr: true},
+		{name: "invalid blob digest", modify: func(a *artifactspec.Artifact) { a.Blobs[0].Digest = "sha256:invalid" }, wantErr: true},
+		{name: "empty subject digest", modify: func(a *artifactspec.Artifact) { a.SubjectManifest.Digest = "" }, wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			artifact := valid()
+			tt.modify(&artifact)
+			err := registry.ValidateArtifact(artifact)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateArtifact() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, notary.ErrInvalidFormat) {
+				t.Errorf("ValidateArtifact() error = %v, want ErrInvalidFormat", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package registry
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/notaryproject/notary/v2"
+)
+
+// ErrorBudgetTransport tolerates up to MaxErrors registry failures within
+// Window. Once the budget is exceeded, requests fail fast with
+// ErrorBudgetExhaustedError without being sent. The budget is replenished
+// gradually as a token bucket, at the rate of MaxErrors per Window, to avoid
+// oscillating at the boundary of fixed windows.
+// Transport errors and 5xx responses are counted as failures.
+type ErrorBudgetTransport struct {
+	Base      http.RoundTripper
+	Window    time.Duration
+	MaxErrors int
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// ErrorBudgetExhaustedError is returned when the error budget is exhausted
+type ErrorBudgetExhaustedError struct {
+	notary.NotaryError
+	Errors int
+	Window time.Duration
+}
+
+func (e *ErrorBudgetExhaustedError) Error() string {
+	return fmt.Sprintf("error budget exhausted: %d errors within %v", e.Errors, e.Window)
+}
+
+// RoundTrip sends the request if the error budget is not exhausted
+func (t *ErrorBudgetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.check(); err != nil {
+		return nil, err
+	}
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil || resp.StatusCode >= http.StatusInternalServerError {
+		t.spend()
+	}
+	return resp, err
+}
+
+func (t *ErrorBudgetTransport) check() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.refill()
+	// a request is sent only if a whole failure can still be afforded
+	if t.tokens < 1 {
+		return &ErrorBudgetExhaustedError{
+			NotaryError: notary.NotaryError{
+				Code: notary.ErrCodeNetwork,
+				Op:   "round trip",
+			},
+			Errors: int(math.Ceil(float64(t.MaxErrors) - t.tokens)),
+			Window: t.Window,
+		}
+	}
+	return nil
+}
+
+func (t *ErrorBudgetTransport) spend() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.refill()
+	t.tokens--
+}
+
+// refill replenishes the budget for the time elapsed since the last refill.
+// The caller must hold the lock.
+func (t *ErrorBudgetTransport) refill() {
+	now := time.Now()
+	if t.last.IsZero() {
+		t.tokens = float64(t.MaxErrors)
+	} else if t.Window > 0 {
+		rate := float64(t.MaxErrors) / float64(t.Window)
+		t.tokens = math.Min(float64(t.MaxErrors), t.tokens+rate*float64(now.Sub(t.last)))
+	}
+	t.last = now
+}
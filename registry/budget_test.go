@@ -0,0 +1,110 @@
+package registry_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/registry"
+)
+
+// newTestBudgetTransport returns an error budget transport on a base replying
+// with the status, and the number of requests sent to the base
+func newTestBudgetTransport(window time.Duration, maxErrors int, status *int) (*registry.ErrorBudgetTransport, *int) {
+	sent := 0
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		sent++
+		return &http.Response{
+			StatusCode: *status,
+			Body:       io.NopCloser(strings.NewReader("")),
+		}, nil
+	})
+	return &registry.ErrorBudgetTransport{
+		Base:      base,
+		Window:    window,
+		MaxErrors: maxErrors,
+	}, &sent
+}
+
+func roundTrip(t *testing.T, tr http.RoundTripper) error {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, "http://registry.example.com/v2/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := tr.RoundTrip(req)
+	if err == nil {
+		resp.Body.Close()
+	}
+	return err
+}
+
+func TestErrorBudgetTransportExhausted(t *testing.T) {
+	status := http.StatusInternalServerError
+	tr, sent := newTestBudgetTransport(time.Hour, 3, &status)
+	for i := 0; i < 3; i++ {
+		if err := roundTrip(t, tr); err != nil {
+			t.Fatalf("RoundTrip() %d error = %v", i, err)
+		}
+	}
+	err := roundTrip(t, tr)
+	var exhausted *registry.ErrorBudgetExhaustedError
+	if !errors.As(err, &exhausted) {
+		t.Fatalf("RoundTrip() error = %v, want ErrorBudgetExhaustedError", err)
+	}
+	if exhausted.Errors != 3 {
+		t.Errorf("Errors = %d, want 3", exhausted.Errors)
+	}
+	if !errors.Is(err, notary.ErrNetwork) {
+		t.Errorf("RoundTrip() error = %v, want ErrNetwork", err)
+	}
+	if *sent != 3 {
+		t.Errorf("sent %d requests, want 3", *sent)
+	}
+}
+
+func TestErrorBudgetTransportReplenished(t *testing.T) {
+	status := http.StatusInternalServerError
+	window := 50 * time.Millisecond
+	tr, _ := newTestBudgetTransport(window, 2, &status)
+	for i := 0; i < 2; i++ {
+		roundTrip(t, tr)
+	}
+	if err := roundTrip(t, tr); err == nil {
+		t.Fatal("RoundTrip() with the budget exhausted succeeded")
+	}
+	time.Sleep(window)
+	status = http.StatusOK
+	if err := roundTrip(t, tr); err != nil {
+		t.Fatalf("RoundTrip() after the window error = %v", err)
+	}
+}
+
+func TestErrorBudgetTransportSuccess(t *testing.T) {
+	status := http.StatusOK
+	tr, sent := newTestBudgetTransport(time.Hour, 1, &status)
+	for i := 0; i < 10; i++ {
+		if err := roundTrip(t, tr); err != nil {
+			t.Fatalf("RoundTrip() %d error = %v", i, err)
+		}
+	}
+	// client errors are no registry failures either
+	status = http.StatusNotFound
+	if err := roundTrip(t, tr); err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+	status = http.StatusInternalServerError
+	if err := roundTrip(t, tr); err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+	if err := roundTrip(t, tr); err == nil {
+		t.Fatal("RoundTrip() with the budget exhausted succeeded")
+	}
+	if *sent != 12 {
+		t.Errorf("sent %d requests, want 12", *sent)
+	}
+}
@@ -0,0 +1,118 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// RegistryCapabilities is a set of the optional features of a registry
+type RegistryCapabilities uint64
+
+// Registry capabilities
+const (
+	// CapReferrers is the referrers API of the OCI distribution spec v1.1
+	CapReferrers RegistryCapabilities = 1 << iota
+
+	// CapReferrersTags is the referrers tag schema fallback of the OCI
+	// distribution spec v1.1
+	CapReferrersTags
+
+	// CapOCIArtifacts is the pre-standard artifacts extension API
+	CapOCIArtifacts
+
+	// CapBlobMount is the cross repository blob mount
+	CapBlobMount
+
+	// CapChunkedUpload is the chunked blob upload
+	CapChunkedUpload
+
+	// CapResumableUpload is the resumption of interrupted blob uploads
+	CapResumableUpload
+)
+
+var capabilityNames = []struct {
+	cap  RegistryCapabilities
+	name string
+}{
+	{CapReferrers, "referrers"},
+	{CapReferrersTags, "referrers-tags"},
+	{CapOCIArtifacts, "oci-artifacts"},
+	{CapBlobMount, "blob-mount"},
+	{CapChunkedUpload, "chunked-upload"},
+	{CapResumableUpload, "resumable-upload"},
+}
+
+// Has reports whether all the capabilities in cap are present
+func (c RegistryCapabilities) Has(cap RegistryCapabilities) bool {
+	return c&cap == cap
+}
+
+func (c RegistryCapabilities) String() string {
+	var names []string
+	for _, capability := range capabilityNames {
+		if c.Has(capability.cap) {
+			names = append(names, capability.name)
+			c &^= capability.cap
+		}
+	}
+	if c != 0 {
+		names = append(names, fmt.Sprintf("0x%x", uint64(c)))
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "|")
+}
+
+// Capabilities returns the capabilities of the registry detected by the last
+// successful Ping, or none if the registry has not been pinged.
+func (r *Repository) Capabilities() RegistryCapabilities {
+	return RegistryCapabilities(atomic.LoadUint64(&r.capabilities))
+}
+
+// detectCapabilities probes the read-only APIs of the optional features.
+// The tag schema fallback is to be used by registries without the referrers
+// API only, which is known from a 404 on the referrers API. It is detected if
+// the referrers tag of a subject can then be looked up, i.e. the registry
+// replies 200 or 404 to it. Any other failure, e.g. a network or an auth
+// error, leaves both undetected.
+// Blob mounts and uploads cannot be probed without writing to the registry,
+// and therefore are not detected.
+func (r *Repository) detectCapabilities(ctx context.Context) RegistryCapabilities {
+	subject := digest.FromBytes(nil)
+	var caps RegistryCapabilities
+	switch r.probe(ctx, fmt.Sprintf("%s/%s/referrers/%s", r.base, r.name, subject)) {
+	case http.StatusOK:
+		caps |= CapReferrers
+	case http.StatusNotFound:
+		tag := subject.Algorithm().String() + "-" + subject.Encoded()
+		switch r.probe(ctx, fmt.Sprintf("%s/%s/manifests/%s", r.base, r.name, tag)) {
+		case http.StatusOK, http.StatusNotFound:
+			caps |= CapReferrersTags
+		}
+	}
+	if r.probe(ctx, fmt.Sprintf("%s/_ext/oci-artifacts/v1-rc1/%s/manifests/%s/referrers", r.base, r.name, subject)) == http.StatusOK {
+		caps |= CapOCIArtifacts
+	}
+	return caps
+}
+
+// probe returns the status code of the GET request to url, or 0 if the
+// request fails
+func (r *Repository) probe(ctx context.Context, url string) int {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0
+	}
+	resp, err := r.tr.RoundTrip(req)
+	if err != nil {
+		return 0
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
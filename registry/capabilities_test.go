@@ -0,0 +1,96 @@
+package registry_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/notaryproject/notary/v2/registry"
+	"github.com/notaryproject/notary/v2/registry/registrytest"
+)
+
+func TestPingCapabilities(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		serverOpts []registrytest.Option
+		want       registry.RegistryCapabilities
+	}{
+		{
+			name: "all APIs",
+			want: registry.CapReferrers | registry.CapOCIArtifacts,
+		},
+		{
+			name:       "artifacts extension only",
+			serverOpts: []registrytest.Option{registrytest.WithoutReferrersAPI()},
+			want:       registry.CapReferrersTags | registry.CapOCIArtifacts,
+		},
+		{
+			name:       "referrers API only",
+			serverOpts: []registrytest.Option{registrytest.WithoutArtifactsExtension()},
+			want:       registry.CapReferrers,
+		},
+		{
+			name:       "neither",
+			serverOpts: []registrytest.Option{registrytest.WithoutReferrersAPI(), registrytest.WithoutArtifactsExtension()},
+			want:       registry.CapReferrersTags,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, _ := newTestRepository(t, tt.serverOpts)
+			if got := repo.Capabilities(); got != 0 {
+				t.Fatalf("Capabilities() before Ping() = %v, want none", got)
+			}
+			if err := repo.Ping(context.Background()); err != nil {
+				t.Fatalf("Ping() error = %v", err)
+			}
+			if got := repo.Capabilities(); got != tt.want {
+				t.Errorf("Capabilities() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPingCapabilitiesUnauthorized(t *testing.T) {
+	// the registry can be pinged anonymously, but denies the repository
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	repo := registry.NewRepository(http.DefaultTransport, strings.TrimPrefix(server.URL, "http://"), "test/app", true)
+	if err := repo.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if got := repo.Capabilities(); got != 0 {
+		t.Errorf("Capabilities() = %v, want none", got)
+	}
+}
+
+func TestRegistryCapabilities(t *testing.T) {
+	for _, tt := range []struct {
+		caps     registry.RegistryCapabilities
+		has      registry.RegistryCapabilities
+		wantHas  bool
+		wantName string
+	}{
+		{caps: 0, has: registry.CapReferrers, wantName: "none"},
+		{caps: registry.CapReferrers, has: registry.CapReferrers, wantHas: true, wantName: "referrers"},
+		{caps: registry.CapReferrers | registry.CapBlobMount, has: registry.CapReferrers | registry.CapBlobMount, wantHas: true, wantName: "referrers|blob-mount"},
+		{caps: registry.CapReferrers, has: registry.CapReferrers | registry.CapOCIArtifacts, wantName: "referrers"},
+		{caps: registry.CapResumableUpload << 1, has: registry.CapReferrers, wantName: "0x40"},
+	} {
+		t.Run(tt.wantName, func(t *testing.T) {
+			if got := tt.caps.Has(tt.has); got != tt.wantHas {
+				t.Errorf("Has(%v) = %v, want %v", tt.has, got, tt.wantHas)
+			}
+			if got := tt.caps.String(); got != tt.wantName {
+				t.Errorf("String() = %s, want %s", got, tt.wantName)
+			}
+		})
+	}
+}
@@ -1,7 +1,12 @@
 package registry
 
 import (
+	"encoding/json"
+	"io"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/opencontainers/go-digest"
 	oci "github.com/opencontainers/image-spec/specs-go/v1"
@@ -15,7 +20,73 @@ func DescriptorFromBytes(data []byte) oci.Descriptor {
 	}
 }
 
-// DescriptorFromFile computes the basic descriptor from the file
+// DescriptorWriter computes the descriptor of the content written through it
+type DescriptorWriter struct {
+	w        io.Writer
+	digester digest.Digester
+	size     int64
+}
+
+// NewDescriptorWriter returns a writer writing to w, which computes the
+// descriptor of the written content on the fly.
+func NewDescriptorWriter(w io.Writer) *DescriptorWriter {
+	return &DescriptorWriter{
+		w:        w,
+		digester: digest.Canonical.Digester(),
+	}
+}
+
+// Write writes p to the underlying writer, and hashes the bytes written
+func (w *DescriptorWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.digester.Hash().Write(p[:n])
+	w.size += int64(n)
+	return n, err
+}
+
+// Descriptor returns the descriptor of the content written so far
+func (w *DescriptorWriter) Descriptor(mediaType string) oci.Descriptor {
+	return oci.Descriptor{
+		MediaType: mediaType,
+		Digest:    w.digester.Digest(),
+		Size:      w.size,
+	}
+}
+
+// DescriptorFromManifest computes the descriptor from the given manifest,
+// including the media type declared in the manifest
+func DescriptorFromManifest(manifest []byte) (oci.Descriptor, error) {
+	var versioned struct {
+		MediaType string `json:"mediaType"`
+	}
+	if err := json.Unmarshal(manifest, &versioned); err != nil {
+		return oci.Descriptor{}, err
+	}
+	desc := DescriptorFromBytes(manifest)
+	desc.MediaType = versioned.MediaType
+	return desc, nil
+}
+
+// fileMediaTypes maps file extensions to media types. It is fixed rather than
+// read from the mime types of the host, so that the same file is described by
+// the same descriptor on every machine.
+var fileMediaTypes = map[string]string{
+	".gz":   "application/gzip",
+	".json": "application/json",
+	".pdf":  "application/pdf",
+	".tar":  "application/x-tar",
+	".tgz":  "application/gzip",
+	".txt":  "text/plain; charset=utf-8",
+	".wasm": "application/wasm",
+	".yaml": "application/yaml",
+	".yml":  "application/yaml",
+	".zip":  "application/zip",
+}
+
+// DescriptorFromFile computes the descriptor from the file in a single
+// streaming pass, without loading the file into memory. The media type is
+// detected from the file extension, or from the leading bytes of the file
+// by http.DetectContentType.
 func DescriptorFromFile(path string) (oci.Descriptor, error) {
 	file, err := os.Open(path)
 	if err != nil {
@@ -23,18 +94,29 @@ func DescriptorFromFile(path string) (oci.Descriptor, error) {
 	}
 	defer file.Close()
 
-	stat, err := file.Stat()
-	if err != nil {
+	// http.DetectContentType considers at most the first 512 bytes
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
 		return oci.Descriptor{}, err
 	}
+	head = head[:n]
 
-	digest, err := digest.FromReader(file)
+	digester := digest.Canonical.Digester()
+	hash := digester.Hash()
+	hash.Write(head)
+	size, err := io.Copy(hash, file)
 	if err != nil {
 		return oci.Descriptor{}, err
 	}
 
+	mediaType, ok := fileMediaTypes[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		mediaType = http.DetectContentType(head)
+	}
 	return oci.Descriptor{
-		Digest: digest,
-		Size:   stat.Size(),
+		MediaType: mediaType,
+		Digest:    digester.Digest(),
+		Size:      int64(n) + size,
 	}, nil
 }
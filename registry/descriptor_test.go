@@ -0,0 +1,86 @@
+package registry_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/notaryproject/notary/v2/registry"
+	"github.com/opencontainers/go-digest"
+)
+
+func TestDescriptorFromFile(t *testing.T) {
+	var gzipped bytes.Buffer
+	zw := gzip.NewWriter(&gzipped)
+	zw.Write([]byte("content"))
+	zw.Close()
+
+	for _, tt := range []struct {
+		name          string
+		file          string
+		content       []byte
+		wantMediaType string
+	}{
+		{name: "known extension", file: "config.json", content: []byte(`{}`), wantMediaType: "application/json"},
+		{name: "upper case extension", file: "CHART.TGZ", content: gzipped.Bytes(), wantMediaType: "application/gzip"},
+		{name: "detected from content", file: "layer.bin", content: gzipped.Bytes(), wantMediaType: "application/x-gzip"},
+		{name: "larger than the detection window", file: "notes", content: bytes.Repeat([]byte("a"), 1024), wantMediaType: "text/plain; charset=utf-8"},
+		{name: "empty", file: "empty", content: nil, wantMediaType: "text/plain; charset=utf-8"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, tt.content, 0600); err != nil {
+				t.Fatal(err)
+			}
+			desc, err := registry.DescriptorFromFile(path)
+			if err != nil {
+				t.Fatalf("DescriptorFromFile() error = %v", err)
+			}
+			if desc.MediaType != tt.wantMediaType {
+				t.Errorf("DescriptorFromFile() media type = %s, want %s", desc.MediaType, tt.wantMediaType)
+			}
+			if want := digest.FromBytes(tt.content); desc.Digest != want {
+				t.Errorf("DescriptorFromFile() digest = %s, want %s", desc.Digest, want)
+			}
+			if desc.Size != int64(len(tt.content)) {
+				t.Errorf("DescriptorFromFile() size = %d, want %d", desc.Size, len(tt.content))
+			}
+		})
+	}
+}
+
+// newBenchmarkFile writes a file of 16 MiB
+func newBenchmarkFile(b *testing.B) string {
+	b.Helper()
+	path := filepath.Join(b.TempDir(), "layer.tar")
+	if err := os.WriteFile(path, bytes.Repeat([]byte{0x5a}, 16<<20), 0600); err != nil {
+		b.Fatal(err)
+	}
+	return path
+}
+
+func BenchmarkDescriptorFromBytes(b *testing.B) {
+	path := newBenchmarkFile(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			b.Fatal(err)
+		}
+		registry.DescriptorFromBytes(data)
+	}
+}
+
+func BenchmarkDescriptorFromFile(b *testing.B) {
+	path := newBenchmarkFile(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := registry.DescriptorFromFile(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
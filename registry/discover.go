@@ -0,0 +1,110 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// DiscoverSubjects finds the manifests in the repository with at least one
+// signature. The distribution spec offers no referrers query without a
+// subject, so the tags of the repository are enumerated and each tagged
+// manifest is looked up for signatures. Untagged manifests are not found.
+func (r *Repository) DiscoverSubjects(ctx context.Context) ([]oci.Descriptor, error) {
+	tags, err := r.Tags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[digest.Digest]bool)
+	var subjects []oci.Descriptor
+	for _, tag := range tags {
+		desc, err := r.Resolve(ctx, tag)
+		if err != nil {
+			return nil, err
+		}
+		if seen[desc.Digest] {
+			continue
+		}
+		seen[desc.Digest] = true
+
+		refs, err := r.lookup(ctx, desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		if len(refs) > 0 {
+			subjects = append(subjects, desc)
+		}
+	}
+	return subjects, nil
+}
+
+// Tags lists the tags of the repository, following the pagination of the
+// registry.
+func (r *Repository) Tags(ctx context.Context) ([]string, error) {
+	next := fmt.Sprintf("%s/%s/tags/list", r.base, r.name)
+	var tags []string
+	for next != "" {
+		var page []string
+		var err error
+		page, next, err = r.tagsPage(ctx, next)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, page...)
+	}
+	return tags, nil
+}
+
+// tagsPage fetches a page of the tag list, and returns the URL of the next
+// page if any.
+func (r *Repository) tagsPage(ctx context.Context, pageURL string) ([]string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := r.tr.RoundTrip(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", statusError("list tags", resp)
+	}
+
+	var result struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxReadLimit)).Decode(&result); err != nil {
+		return nil, "", err
+	}
+	next, err := nextPageURL(req.URL, resp.Header.Get("Link"))
+	if err != nil {
+		return nil, "", err
+	}
+	return result.Tags, next, nil
+}
+
+// nextPageURL parses the Link header of the form <url>; rel="next", and
+// resolves the URL against the URL of the current page.
+func nextPageURL(current *url.URL, link string) (string, error) {
+	if link == "" {
+		return "", nil
+	}
+	start := strings.Index(link, "<")
+	end := strings.Index(link, ">")
+	if start < 0 || end < start || !strings.Contains(link[end:], `rel="next"`) {
+		return "", fmt.Errorf("invalid link header: %s", link)
+	}
+	next, err := current.Parse(link[start+1 : end])
+	if err != nil {
+		return "", err
+	}
+	return next.String(), nil
+}
@@ -0,0 +1,94 @@
+package registry_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/notaryproject/notary/v2/registry"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestDiscoverSubjects(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newTestRepository(t, nil)
+	put := func(content, tag string) oci.Descriptor {
+		t.Helper()
+		desc, err := repo.PutTaggedManifest(ctx, []byte(content), oci.MediaTypeImageManifest, tag)
+		if err != nil {
+			t.Fatalf("PutTaggedManifest() error = %v", err)
+		}
+		return desc
+	}
+	signed := put(`{"schemaVersion":2,"signed":true}`, "v1")
+	put(`{"schemaVersion":2,"signed":true}`, "latest")
+	put(`{"schemaVersion":2}`, "v2")
+	if _, err := repo.Link(ctx, signed, putTestSignature(t, repo, "signature")); err != nil {
+		t.Fatalf("Link() error = %v", err)
+	}
+
+	tags, err := repo.Tags(ctx)
+	if err != nil {
+		t.Fatalf("Tags() error = %v", err)
+	}
+	if want := []string{"latest", "v1", "v2"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("Tags() = %v, want %v", tags, want)
+	}
+
+	// the signed manifest is tagged twice, but found once
+	subjects, err := repo.DiscoverSubjects(ctx)
+	if err != nil {
+		t.Fatalf("DiscoverSubjects() error = %v", err)
+	}
+	if len(subjects) != 1 || subjects[0].Digest != signed.Digest {
+		t.Errorf("DiscoverSubjects() = %v, want %v", subjects, signed.Digest)
+	}
+}
+
+func TestTagsPagination(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		link    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "next page", link: `</v2/test/app/tags/list?last=v2&n=2>; rel="next"`, want: []string{"v1", "v2", "v3"}},
+		{name: "invalid link", link: `/v2/test/app/tags/list?last=v2`, wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("last") == "v2" {
+					fmt.Fprint(w, `{"tags":["v3"]}`)
+					return
+				}
+				w.Header().Set("Link", tt.link)
+				fmt.Fprint(w, `{"tags":["v1","v2"]}`)
+			}))
+			defer server.Close()
+			repo := registry.NewRepository(http.DefaultTransport, strings.TrimPrefix(server.URL, "http://"), "test/app", true)
+			got, err := repo.Tags(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Tags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagsError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	repo := registry.NewRepository(http.DefaultTransport, strings.TrimPrefix(server.URL, "http://"), "test/app", true)
+	if _, err := repo.Tags(context.Background()); err == nil {
+		t.Error("Tags() error = nil, want an error")
+	}
+	if _, err := repo.DiscoverSubjects(context.Background()); err == nil {
+		t.Error("DiscoverSubjects() error = nil, want an error")
+	}
+}
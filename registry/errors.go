@@ -0,0 +1,75 @@
+package registry
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/opencontainers/go-digest"
+)
+
+// ManifestIntegrityError is returned when a manifest served by the registry
+// does not match its content-addressable identity.
+type ManifestIntegrityError struct {
+	notary.NotaryError
+	Expected digest.Digest
+	Actual   digest.Digest
+}
+
+func (e *ManifestIntegrityError) Error() string {
+	return fmt.Sprintf("manifest integrity check failed: expect %v: got %v", e.Expected, e.Actual)
+}
+
+// UnexpectedMediaTypeError is returned when a manifest served by the registry
+// is of none of the requested media types.
+type UnexpectedMediaTypeError struct {
+	notary.NotaryError
+	MediaType string
+	Accepted  []string
+}
+
+func (e *UnexpectedMediaTypeError) Error() string {
+	return fmt.Sprintf("unexpected manifest media type: %q: accepted %s", e.MediaType, strings.Join(e.Accepted, ", "))
+}
+
+// maxErrorDetail is the length of the error details in the response body
+// kept in the returned errors
+const maxErrorDetail = 1024
+
+// responseError returns the statusError of the response, adding the error
+// details the registry returned in the response body. The body is read up to
+// maxReadLimit so that a misbehaving registry cannot block the caller with an
+// unbounded body.
+func responseError(op string, resp *http.Response) error {
+	err := statusError(op, resp)
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxReadLimit))
+	detail := strings.TrimSpace(string(body))
+	if detail == "" {
+		return err
+	}
+	if len(detail) > maxErrorDetail {
+		detail = detail[:maxErrorDetail] + "..."
+	}
+	return fmt.Errorf("%w: %s", err, detail)
+}
+
+// statusError returns the error for an unexpected registry response, which
+// matches notary.ErrNotFound or notary.ErrUnauthorized where applicable.
+func statusError(op string, resp *http.Response) error {
+	var code notary.ErrorCode
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		code = notary.ErrCodeNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		code = notary.ErrCodeUnauthorized
+	default:
+		return fmt.Errorf("failed to %s: %s", op, resp.Status)
+	}
+	return &notary.NotaryError{
+		Code:  code,
+		Op:    op,
+		Cause: fmt.Errorf("registry responded: %s", resp.Status),
+	}
+}
@@ -9,6 +9,10 @@ import (
 
 const maxReadLimit = 4 * 1024 * 1024
 
+// MaxManifestSize is the largest manifest pushed to the registry, which is
+// the minimum size registries are required to accept by the distribution spec.
+const MaxManifestSize = 4 * 1024 * 1024
+
 func readAllVerified(r io.Reader, expected digest.Digest) ([]byte, error) {
 	digester := expected.Algorithm().Digester()
 	content, err := io.ReadAll(io.TeeReader(
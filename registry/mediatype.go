@@ -1,5 +1,10 @@
 package registry
 
+import (
+	artifactspec "github.com/opencontainers/artifacts/specs-go/v2"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
 const (
 	// ArtifactTypeNotaryV2 specifies the artifact type for a notary V2 object.
 	ArtifactTypeNotaryV2 = "application/vnd.cncf.notary.v2"
@@ -8,4 +13,35 @@ const (
 const (
 	// MediaTypeNotarySignature specifies the media type for the notary signature.
 	MediaTypeNotarySignature = "application/vnd.cncf.notary.signature.v2+jwt"
+
+	// MediaTypeNotarySignatureLayer specifies the media type for the notary
+	// signature stored as a layer of an image manifest.
+	MediaTypeNotarySignatureLayer = "application/vnd.cncf.notary.signature.v1"
+)
+
+const (
+	// MediaTypeOCIArtifactManifest specifies the media type for the OCI v1.1 artifact manifest.
+	MediaTypeOCIArtifactManifest = "application/vnd.oci.artifact.manifest.v1+json"
 )
+
+const (
+	// MediaTypeDockerManifest specifies the media type for the docker image manifest v2 schema 2.
+	MediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+
+	// MediaTypeDockerManifestList specifies the media type for the docker manifest list.
+	MediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+	// MediaTypeDockerManifestSchema1Signed specifies the media type for the
+	// signed docker image manifest v2 schema 1.
+	MediaTypeDockerManifestSchema1Signed = "application/vnd.docker.distribution.manifest.v1+prettyjws"
+)
+
+// manifestMediaTypes are the manifest media types accepted from registries
+var manifestMediaTypes = []string{
+	oci.MediaTypeImageManifest,
+	oci.MediaTypeImageIndex,
+	MediaTypeDockerManifest,
+	MediaTypeDockerManifestList,
+	artifactspec.MediaTypeArtifactManifest,
+	MediaTypeOCIArtifactManifest,
+}
@@ -0,0 +1,131 @@
+package registry
+
+import (
+	"net"
+	"net/http"
+)
+
+// RepositoryOption configures the access to the repositories of a registry
+type RepositoryOption func(*repositoryOptions)
+
+type repositoryOptions struct {
+	insecureHosts  map[string]bool
+	insecureNotice func(req *http.Request)
+	userAgent      string
+	referrersAPI   bool
+	dialer         *net.Dialer
+
+	// maxConnsPerHost limits the connections shared by the repositories
+	maxConnsPerHost int
+
+	// linkConcurrency limits the concurrent links of BatchLink
+	linkConcurrency int
+
+	// successStatuses are the status codes accepted for manifest pushes
+	successStatuses []int
+}
+
+// WithInsecureRegistry allows the listed registry hosts to be accessed over
+// plain HTTP. It is intended for local development registries only. Every
+// request to an insecure registry is logged as a warning, unless replaced by
+// WithInsecureNotice.
+func WithInsecureRegistry(hosts ...string) RepositoryOption {
+	return func(opts *repositoryOptions) {
+		if opts.insecureHosts == nil {
+			opts.insecureHosts = make(map[string]bool)
+		}
+		for _, host := range hosts {
+			opts.insecureHosts[host] = true
+		}
+	}
+}
+
+// WithInsecureNotice calls notice before every request sent to a registry
+// allowed by WithInsecureRegistry instead of logging a warning, e.g. to report
+// it with the logger of the application. A nil notice disables the warning.
+func WithInsecureNotice(notice func(req *http.Request)) RepositoryOption {
+	return func(opts *repositoryOptions) {
+		opts.insecureNotice = notice
+	}
+}
+
+// WithUserAgent appends ua to the default User-Agent header sent with every
+// request. If ua is empty, no User-Agent header is sent at all.
+func WithUserAgent(ua string) RepositoryOption {
+	return func(opts *repositoryOptions) {
+		if ua == "" {
+			opts.userAgent = ""
+			return
+		}
+		opts.userAgent = defaultUserAgent + " " + ua
+	}
+}
+
+// WithReferrersAPI links and looks up signatures with the referrers API and
+// the artifact manifests of the OCI distribution spec v1.1, instead of the
+// pre-standard artifacts extension API.
+func WithReferrersAPI() RepositoryOption {
+	return func(opts *repositoryOptions) {
+		opts.referrersAPI = true
+	}
+}
+
+// WithSuccessStatuses sets the status codes accepted as success when pushing
+// manifests. It defaults to 201 Created as required by the distribution spec,
+// and 202 Accepted replied by some registries, e.g. older versions of Harbor.
+// A 409 Conflict for a manifest which already exists with the same content is
+// always accepted.
+func WithSuccessStatuses(statuses ...int) RepositoryOption {
+	return func(opts *repositoryOptions) {
+		opts.successStatuses = statuses
+	}
+}
+
+// WithCustomDialer dials the registry with d instead of the default dialer of
+// the transport, e.g. to resolve cluster-internal registry hostnames.
+// It applies only if the transport is an *http.Transport or nil, in which case
+// a copy of http.DefaultTransport is used. With any other transport, every
+// request fails with an error.
+func WithCustomDialer(d *net.Dialer) RepositoryOption {
+	return func(opts *repositoryOptions) {
+		opts.dialer = d
+	}
+}
+
+// WithCustomResolver resolves the registry hostname with resolver instead of
+// the system resolver. See WithCustomDialer for the supported transports.
+func WithCustomResolver(resolver *net.Resolver) RepositoryOption {
+	return WithCustomDialer(&net.Dialer{
+		Resolver: resolver,
+	})
+}
+
+// WithMaxConnsPerHost limits the number of connections to the registry host,
+// which are shared by all the repositories accessed through the same client.
+// See WithCustomDialer for the supported transports.
+func WithMaxConnsPerHost(n int) RepositoryOption {
+	return func(opts *repositoryOptions) {
+		opts.maxConnsPerHost = n
+	}
+}
+
+// WithLinkConcurrency limits the number of artifact manifests pushed
+// concurrently by BatchLink, e.g. for rate-limited registries. It defaults to
+// the number of signatures, up to GOMAXPROCS.
+func WithLinkConcurrency(n int) RepositoryOption {
+	return func(opts *repositoryOptions) {
+		opts.linkConcurrency = n
+	}
+}
+
+func newRepositoryOptions(opts []RepositoryOption) repositoryOptions {
+	options := repositoryOptions{
+		insecureNotice:  warnInsecure,
+		userAgent:       defaultUserAgent,
+		successStatuses: []int{http.StatusCreated, http.StatusAccepted},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
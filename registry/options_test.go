@@ -0,0 +1,151 @@
+package registry_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/registry"
+	"github.com/notaryproject/notary/v2/registry/registrytest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestWithCustomDialerUnsupportedTransport(t *testing.T) {
+	server := registrytest.NewServer()
+	defer server.Close()
+	wrapped := roundTripperFunc(http.DefaultTransport.RoundTrip)
+	repo := registry.NewRepository(wrapped, server.Host(), "test/app", true, registry.WithCustomDialer(&net.Dialer{}))
+	err := repo.Ping(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "custom dialer") {
+		t.Fatalf("Ping() error = %v, want the unsupported option", err)
+	}
+}
+
+func TestWithInsecureNotice(t *testing.T) {
+	server := registrytest.NewServer()
+	defer server.Close()
+	var notified []string
+	repo := registry.NewRepository(http.DefaultTransport, server.Host(), "test/app", false,
+		registry.WithInsecureRegistry(server.Host()),
+		registry.WithInsecureNotice(func(req *http.Request) {
+			notified = append(notified, req.URL.Host)
+		}),
+	)
+	if err := repo.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if len(notified) == 0 {
+		t.Fatal("no request notified")
+	}
+	for _, host := range notified {
+		if host != server.Host() {
+			t.Errorf("notified host = %s, want %s", host, server.Host())
+		}
+	}
+}
+
+func TestInsecureRegistryWarning(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+	server := registrytest.NewServer()
+	defer server.Close()
+	repo := registry.NewRepository(http.DefaultTransport, server.Host(), "test/app", false,
+		registry.WithInsecureRegistry(server.Host()),
+	)
+	if err := repo.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if got := logs.String(); !strings.Contains(got, "WARNING") || !strings.Contains(got, server.Host()) {
+		t.Errorf("log = %q, want a warning for %s", got, server.Host())
+	}
+
+	logs.Reset()
+	repo = registry.NewRepository(http.DefaultTransport, server.Host(), "test/app", false,
+		registry.WithInsecureRegistry(server.Host()),
+		registry.WithInsecureNotice(nil),
+	)
+	if err := repo.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if got := logs.String(); got != "" {
+		t.Errorf("log = %q, want no warning with a nil notice", got)
+	}
+}
+
+func TestWithUserAgent(t *testing.T) {
+	defaultUserAgent := fmt.Sprintf("notary/%s (%s/%s)", notary.Version, runtime.GOOS, runtime.GOARCH)
+	for _, tt := range []struct {
+		name string
+		opts []registry.RepositoryOption
+		want string
+	}{
+		{name: "default", want: defaultUserAgent},
+		{name: "custom", opts: []registry.RepositoryOption{registry.WithUserAgent("ci/1.0")}, want: defaultUserAgent + " ci/1.0"},
+		{name: "empty", opts: []registry.RepositoryOption{registry.WithUserAgent("")}, want: ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, server := newTestRepository(t, nil, tt.opts...)
+			if err := repo.Ping(context.Background()); err != nil {
+				t.Fatalf("Ping() error = %v", err)
+			}
+			if got := server.LastHeader().Get("User-Agent"); got != tt.want {
+				t.Errorf("User-Agent = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithSuccessStatuses(t *testing.T) {
+	fake := registrytest.NewServer()
+	defer fake.Close()
+	// the registry accepts manifest pushes with 202 like older Harbor versions
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || !strings.Contains(r.URL.Path, "/manifests/") {
+			fake.Config.Handler.ServeHTTP(w, r)
+			return
+		}
+		rec := httptest.NewRecorder()
+		fake.Config.Handler.ServeHTTP(rec, r)
+		status := rec.Code
+		if status == http.StatusCreated {
+			status = http.StatusAccepted
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+	manifest := []byte(`{"schemaVersion":2}`)
+	for _, tt := range []struct {
+		name    string
+		opts    []registry.RepositoryOption
+		wantErr bool
+	}{
+		{name: "default"},
+		{name: "permissive", opts: []registry.RepositoryOption{registry.WithSuccessStatuses(http.StatusCreated, http.StatusAccepted)}},
+		{name: "strict", opts: []registry.RepositoryOption{registry.WithSuccessStatuses(http.StatusCreated)}, wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := registry.NewRepository(http.DefaultTransport, host, "test/app", true, tt.opts...)
+			_, err := repo.PutManifest(context.Background(), manifest, oci.MediaTypeImageManifest)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PutManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// Docker Hub defaults applied to short image references
+const (
+	dockerHubDomain     = "docker.io"
+	dockerHubRegistry   = "registry-1.docker.io"
+	dockerHubRepoPrefix = "library/"
+	defaultTag          = "latest"
+)
+
+// The grammar of image references of github.com/distribution/reference
+const (
+	domainComponentPattern = `(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])`
+	domainPattern          = domainComponentPattern + `(?:\.` + domainComponentPattern + `)*(?::[0-9]+)?`
+	pathComponentPattern   = `[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*`
+	namePattern            = `(?:` + domainPattern + `/)?` + pathComponentPattern + `(?:/` + pathComponentPattern + `)*`
+	tagPattern             = `[\w][\w.-]{0,127}`
+	digestPattern          = `[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,}`
+
+	// maxNameLength is the maximum length of a repository name including
+	// the registry
+	maxNameLength = 255
+)
+
+var (
+	// referenceRegexp matches an image reference, capturing the name, the
+	// tag and the digest
+	referenceRegexp = regexp.MustCompile(`^(` + namePattern + `)(?::(` + tagPattern + `))?(?:@(` + digestPattern + `))?$`)
+
+	// anchoredIdentifierRegexp matches a bare image ID, which is not a valid
+	// repository name
+	anchoredIdentifierRegexp = regexp.MustCompile(`^[a-f0-9]{64}$`)
+)
+
+// NewRepositoryFromDockerRef creates a client to the repository of an image
+// reference like docker.io/library/ubuntu:22.04, and returns the tag or the
+// digest of the reference, which defaults to the latest tag.
+// References without a registry, e.g. ubuntu:22.04, refer to Docker Hub,
+// where single component names refer to the official images under library/.
+// Requests are sent through tr, which should add the credentials required by
+// the registry. If tr is nil, http.DefaultTransport is used and requests are
+// anonymous.
+func NewRepositoryFromDockerRef(tr http.RoundTripper, ref string, opts ...RepositoryOption) (*Repository, string, error) {
+	registryName, name, reference, err := parseDockerRef(ref)
+	if err != nil {
+		return nil, "", err
+	}
+	if tr == nil {
+		tr = http.DefaultTransport
+	}
+	return NewRepository(tr, registryName, name, false, opts...), reference, nil
+}
+
+// parseDockerRef splits an image reference into the registry host, the
+// repository name and the tag or digest, normalizing it the way
+// github.com/distribution/reference does for Docker Hub. If the reference
+// has both a tag and a digest, the digest is returned.
+func parseDockerRef(ref string) (registryName, name, reference string, err error) {
+	match := referenceRegexp.FindStringSubmatch(ref)
+	if match == nil {
+		if referenceRegexp.MatchString(strings.ToLower(ref)) {
+			return "", "", "", fmt.Errorf("invalid reference %q: repository name must be lowercase", ref)
+		}
+		return "", "", "", fmt.Errorf("invalid reference %q", ref)
+	}
+	remainder, tag, reference := match[1], match[2], match[3]
+	if len(remainder) > maxNameLength {
+		return "", "", "", fmt.Errorf("invalid reference %q: repository name longer than %d characters", ref, maxNameLength)
+	}
+	if anchoredIdentifierRegexp.MatchString(remainder) {
+		return "", "", "", fmt.Errorf("invalid reference %q: cannot specify 64-byte hexadecimal strings", ref)
+	}
+	if reference != "" {
+		if _, err := digest.Parse(reference); err != nil {
+			return "", "", "", fmt.Errorf("invalid reference %q: %v", ref, err)
+		}
+	} else if tag != "" {
+		reference = tag
+	} else {
+		reference = defaultTag
+	}
+
+	// the first component is the registry only if it looks like a host
+	registryName = dockerHubDomain
+	name = remainder
+	if i := strings.Index(remainder, "/"); i >= 0 {
+		domain := remainder[:i]
+		if strings.ContainsAny(domain, ".:") || domain == "localhost" || strings.ToLower(domain) != domain {
+			registryName = domain
+			name = remainder[i+1:]
+		}
+	}
+	if registryName == dockerHubDomain || registryName == "index."+dockerHubDomain {
+		registryName = dockerHubRegistry
+		if !strings.Contains(name, "/") {
+			name = dockerHubRepoPrefix + name
+		}
+	}
+	return registryName, name, reference, nil
+}
@@ -0,0 +1,49 @@
+package registry
+
+import "testing"
+
+func TestParseDockerRef(t *testing.T) {
+	const testDigest = "sha256:6c3c624b58dbbcd3c0dd82b4c53f04194d1247c6eebdaab7c610cf7d66709b3b"
+	for _, tt := range []struct {
+		ref          string
+		wantRegistry string
+		wantName     string
+		wantRef      string
+		wantErr      bool
+	}{
+		{ref: "ubuntu", wantRegistry: "registry-1.docker.io", wantName: "library/ubuntu", wantRef: "latest"},
+		{ref: "ubuntu:22.04", wantRegistry: "registry-1.docker.io", wantName: "library/ubuntu", wantRef: "22.04"},
+		{ref: "docker.io/library/ubuntu:22.04", wantRegistry: "registry-1.docker.io", wantName: "library/ubuntu", wantRef: "22.04"},
+		{ref: "index.docker.io/ubuntu", wantRegistry: "registry-1.docker.io", wantName: "library/ubuntu", wantRef: "latest"},
+		{ref: "myorg/myimage:v1.0.0", wantRegistry: "registry-1.docker.io", wantName: "myorg/myimage", wantRef: "v1.0.0"},
+		{ref: "localhost/app", wantRegistry: "localhost", wantName: "app", wantRef: "latest"},
+		{ref: "localhost:5000/team/app:dev", wantRegistry: "localhost:5000", wantName: "team/app", wantRef: "dev"},
+		{ref: "example.azurecr.io/app@" + testDigest, wantRegistry: "example.azurecr.io", wantName: "app", wantRef: testDigest},
+		{ref: "example.azurecr.io/app:v1@" + testDigest, wantRegistry: "example.azurecr.io", wantName: "app", wantRef: testDigest},
+		{ref: "Example/app", wantRegistry: "Example", wantName: "app", wantRef: "latest"},
+		{ref: "", wantErr: true},
+		{ref: "Ubuntu", wantErr: true},
+		{ref: "example.com/App", wantErr: true},
+		{ref: "ubuntu:", wantErr: true},
+		{ref: "ubuntu:-tag", wantErr: true},
+		{ref: "ubuntu@sha256:abc", wantErr: true},
+		{ref: "example.com/app//nested", wantErr: true},
+		{ref: "6c3c624b58dbbcd3c0dd82b4c53f04194d1247c6eebdaab7c610cf7d66709b3b", wantErr: true},
+	} {
+		t.Run(tt.ref, func(t *testing.T) {
+			registryName, name, reference, err := parseDockerRef(tt.ref)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseDockerRef() = %q, %q, %q, want error", registryName, name, reference)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDockerRef() error = %v", err)
+			}
+			if registryName != tt.wantRegistry || name != tt.wantName || reference != tt.wantRef {
+				t.Fatalf("parseDockerRef() = %q, %q, %q, want %q, %q, %q", registryName, name, reference, tt.wantRegistry, tt.wantName, tt.wantRef)
+			}
+		})
+	}
+}
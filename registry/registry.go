@@ -4,32 +4,113 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/notaryproject/notary/v2"
 )
 
-type registry struct {
-	tr   http.RoundTripper
-	base string
+// Registry is a client to a remote registry, whose repositories share its
+// transport, and hence the connections and any credentials cached by the
+// transport, e.g. bearer tokens. The connections to the registry host can be
+// limited by WithMaxConnsPerHost.
+type Registry struct {
+	tr              http.RoundTripper
+	presignedTr     http.RoundTripper
+	base            string
+	referrersAPI    bool
+	successStatuses []int
+	linkConcurrency int
 }
 
 // NewClient creates a client to the remote registry
 // for accessing the signatures.
-func NewClient(tr http.RoundTripper, name string, plainHTTP bool) notary.SignatureRegistry {
-	scheme := "https"
-	if plainHTTP {
+// The repositories obtained from the client share its transport, and hence
+// the connections and any credentials cached by the transport.
+func NewClient(tr http.RoundTripper, name string, plainHTTP bool, opts ...RepositoryOption) notary.SignatureRegistry {
+	return newRegistry(tr, name, plainHTTP, opts)
+}
+
+// NewRegistry creates a client to the remote registry, which creates clients
+// to its repositories sharing its transport. The registry is named as for
+// NewRepository.
+func NewRegistry(tr http.RoundTripper, name string, plainHTTP bool, opts ...RepositoryOption) *Registry {
+	return newRegistry(tr, name, plainHTTP, opts)
+}
+
+// NewRepository creates a client to the repository in the remote registry
+// for accessing the signatures.
+// The registry is named by its host, or by its URL as accepted by
+// ParseRepositoryURL, whose scheme takes precedence over plainHTTP.
+func NewRepository(tr http.RoundTripper, registryName, name string, plainHTTP bool, opts ...RepositoryOption) *Repository {
+	return newRegistry(tr, registryName, plainHTTP, opts).repository(name)
+}
+
+func newRegistry(tr http.RoundTripper, name string, plainHTTP bool, opts []RepositoryOption) *Registry {
+	options := newRepositoryOptions(opts)
+	if options.dialer != nil {
+		tr = withDialer(tr, options.dialer)
+	}
+	if options.maxConnsPerHost > 0 {
+		tr = withMaxConnsPerHost(tr, options.maxConnsPerHost)
+	}
+	// presigned URLs point to the storage service rather than the registry
+	presignedTr := &userAgentTransport{
+		base:      tr,
+		userAgent: options.userAgent,
+	}
+	scheme, host, basePath, err := ParseRepositoryURL(name)
+	if err != nil {
+		// the invalid name is left to fail the requests
+		scheme, host, basePath = "https", name, "/v2"
+	}
+	switch {
+	case strings.Contains(name, "://"):
+		// the scheme of the URL takes precedence
+	case plainHTTP:
 		scheme = "http"
+	case options.insecureHosts[host]:
+		scheme = "http"
+		if options.insecureNotice != nil {
+			tr = &insecureTransport{
+				base:   tr,
+				notice: options.insecureNotice,
+			}
+		}
+	}
+	tr = &userAgentTransport{
+		base:      tr,
+		userAgent: options.userAgent,
 	}
-	return &registry{
-		tr:   tr,
-		base: fmt.Sprintf("%s://%s/v2", scheme, name),
+	return &Registry{
+		tr:              tr,
+		presignedTr:     presignedTr,
+		base:            fmt.Sprintf("%s://%s%s", scheme, host, basePath),
+		referrersAPI:    options.referrersAPI,
+		successStatuses: options.successStatuses,
+		linkConcurrency: options.linkConcurrency,
 	}
 }
 
-func (r *registry) Repository(ctx context.Context, name string) notary.SignatureRepository {
-	return &repository{
-		tr:   r.tr,
-		base: r.base,
-		name: name,
+// Repository returns the signature repository of the given name
+func (r *Registry) Repository(ctx context.Context, name string) notary.SignatureRepository {
+	return r.repository(name)
+}
+
+// NewRepository creates a client to the repository of the given name, sharing
+// the transport of the registry
+func (r *Registry) NewRepository(name string) *Repository {
+	return r.repository(name)
+}
+
+func (r *Registry) repository(name string) *Repository {
+	return &Repository{
+		tr:              r.tr,
+		presignedTr:     r.presignedTr,
+		base:            r.base,
+		name:            name,
+		referrersAPI:    r.referrersAPI,
+		successStatuses: r.successStatuses,
+		linkConcurrency: r.linkConcurrency,
+		stats:           &stats{},
 	}
 }
@@ -0,0 +1,37 @@
+package registry_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/notaryproject/notary/v2/registry"
+	"github.com/notaryproject/notary/v2/registry/registrytest"
+)
+
+func TestRegistrySharedTransport(t *testing.T) {
+	server := registrytest.NewServer()
+	defer server.Close()
+	var requests int64
+	tr := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt64(&requests, 1)
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	reg := registry.NewRegistry(tr, server.Host(), true)
+
+	ctx := context.Background()
+	for _, name := range []string{"team/app", "team/lib"} {
+		repo := reg.NewRepository(name)
+		if got, want := repo.FullName(), server.Host()+"/"+name; got != want {
+			t.Errorf("FullName() = %s, want %s", got, want)
+		}
+		before := atomic.LoadInt64(&requests)
+		if err := repo.Ping(ctx); err != nil {
+			t.Fatalf("Ping() error = %v", err)
+		}
+		if atomic.LoadInt64(&requests) == before {
+			t.Errorf("repository %s does not use the transport of the registry", name)
+		}
+	}
+}
@@ -0,0 +1,356 @@
+package registrytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+
+	artifactspec "github.com/opencontainers/artifacts/specs-go/v2"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// extPrefix is the path prefix of the artifacts extension API
+const extPrefix = "_ext/oci-artifacts/v1-rc1/"
+
+// Option configures the server
+type Option func(*Server)
+
+// WithoutReferrersAPI disables the referrers API of the OCI distribution spec
+// v1.1, like registries predating it.
+func WithoutReferrersAPI() Option {
+	return func(s *Server) {
+		s.referrersAPI = false
+	}
+}
+
+// WithoutArtifactsExtension disables the pre-standard artifacts extension API
+func WithoutArtifactsExtension() Option {
+	return func(s *Server) {
+		s.extAPI = false
+	}
+}
+
+// WithTLS serves HTTPS with a self-signed certificate trusted by the
+// transport of the client returned by Client.
+func WithTLS() Option {
+	return func(s *Server) {
+		s.tls = true
+	}
+}
+
+// Server is an in-memory registry implementing the parts of the OCI
+// distribution spec used by notary, for testing without a real registry.
+type Server struct {
+	*httptest.Server
+
+	referrersAPI bool
+	extAPI       bool
+	tls          bool
+
+	mu         sync.Mutex
+	blobs      map[digest.Digest][]byte
+	manifests  map[string]map[digest.Digest]manifest
+	tags       map[string]map[string]digest.Digest
+	referrers  map[string]map[digest.Digest][]digest.Digest
+	lastHeader http.Header
+}
+
+type manifest struct {
+	content      []byte
+	mediaType    string
+	artifactType string
+}
+
+// NewServer starts a registry with both the referrers API and the artifacts
+// extension API enabled. The server should be closed when the test is done.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		referrersAPI: true,
+		extAPI:       true,
+		blobs:        make(map[digest.Digest][]byte),
+		manifests:    make(map[string]map[digest.Digest]manifest),
+		tags:         make(map[string]map[string]digest.Digest),
+		referrers:    make(map[string]map[digest.Digest][]digest.Digest),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.tls {
+		s.Server = httptest.NewTLSServer(http.HandlerFunc(s.serveHTTP))
+	} else {
+		s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	}
+	return s
+}
+
+// Host returns the host of the registry, to be accessed over plain HTTP
+// unless the server is started with WithTLS
+func (s *Server) Host() string {
+	return strings.TrimPrefix(strings.TrimPrefix(s.URL, "http://"), "https://")
+}
+
+// LastHeader returns the header of the last request received
+func (s *Server) LastHeader() http.Header {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastHeader
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastHeader = r.Header.Clone()
+
+	path := strings.TrimPrefix(r.URL.Path, "/v2/")
+	switch {
+	case r.URL.Path == "/v2/" || r.URL.Path == "/v2":
+		w.WriteHeader(http.StatusOK)
+	case strings.HasPrefix(path, extPrefix):
+		s.serveExtReferrers(w, r, strings.TrimPrefix(path, extPrefix))
+	case strings.HasSuffix(path, "/blobs/uploads/"):
+		s.serveUploadInit(w, r, strings.TrimSuffix(path, "/blobs/uploads/"))
+	case strings.Contains(path, "/blobs/uploads/"):
+		s.serveUpload(w, r)
+	case strings.Contains(path, "/blobs/"):
+		s.serveBlob(w, r, path[strings.LastIndex(path, "/blobs/")+len("/blobs/"):])
+	case strings.HasSuffix(path, "/tags/list"):
+		s.serveTags(w, r, strings.TrimSuffix(path, "/tags/list"))
+	case strings.Contains(path, "/manifests/"):
+		i := strings.LastIndex(path, "/manifests/")
+		s.serveManifest(w, r, path[:i], path[i+len("/manifests/"):])
+	case strings.Contains(path, "/referrers/") && s.referrersAPI:
+		i := strings.LastIndex(path, "/referrers/")
+		s.serveReferrers(w, r, path[:i], path[i+len("/referrers/"):])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) serveUploadInit(w http.ResponseWriter, r *http.Request, name string) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	// the blobs are shared by all repositories, so any known blob is mounted
+	if mount := digest.Digest(r.URL.Query().Get("mount")); mount != "" {
+		if _, ok := s.blobs[mount]; ok {
+			w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/%s", name, mount))
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+	}
+	scheme := "http"
+	if s.tls {
+		scheme = "https"
+	}
+	w.Header().Set("Location", fmt.Sprintf("%s://%s/v2/%s/blobs/uploads/%d", scheme, r.Host, name, len(s.blobs)))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) serveUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	expected, err := digest.Parse(r.URL.Query().Get("digest"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	content, err := io.ReadAll(r.Body)
+	if err != nil || digest.FromBytes(content) != expected {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.blobs[expected] = content
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) serveBlob(w http.ResponseWriter, r *http.Request, reference string) {
+	content, ok := s.blobs[digest.Digest(reference)]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Docker-Content-Digest", reference)
+	w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+	if r.Method == http.MethodGet {
+		w.Write(content)
+	}
+}
+
+func (s *Server) serveManifest(w http.ResponseWriter, r *http.Request, name, reference string) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		d := s.resolve(name, reference)
+		m, ok := s.manifests[name][d]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", m.mediaType)
+		w.Header().Set("Docker-Content-Digest", d.String())
+		w.Header().Set("Content-Length", fmt.Sprint(len(m.content)))
+		if r.Method == http.MethodGet {
+			w.Write(m.content)
+		}
+	case http.MethodPut:
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		d := digest.FromBytes(content)
+		if parsed, err := digest.Parse(reference); err == nil && parsed != d {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.putManifest(name, reference, d, content, r.Header.Get("Content-Type"))
+		w.Header().Set("Docker-Content-Digest", d.String())
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodDelete:
+		d := s.resolve(name, reference)
+		if _, ok := s.manifests[name][d]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+		delete(s.manifests[name], d)
+		for subject, referrers := range s.referrers[name] {
+			s.referrers[name][subject] = removeDigest(referrers, d)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// serveTags lists the tags of the repository in lexical order, without
+// pagination
+func (s *Server) serveTags(w http.ResponseWriter, r *http.Request, name string) {
+	tags := []string{}
+	for tag := range s.tags[name] {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"name": name,
+		"tags": tags,
+	})
+}
+
+func (s *Server) resolve(name, reference string) digest.Digest {
+	if d, err := digest.Parse(reference); err == nil {
+		return d
+	}
+	return s.tags[name][reference]
+}
+
+func (s *Server) putManifest(name, reference string, d digest.Digest, content []byte, mediaType string) {
+	var fields struct {
+		ArtifactType    string          `json:"artifactType"`
+		Config          *oci.Descriptor `json:"config"`
+		Subject         *oci.Descriptor `json:"subject"`
+		SubjectManifest *oci.Descriptor `json:"subjectManifest"`
+	}
+	json.Unmarshal(content, &fields)
+	m := manifest{
+		content:      content,
+		mediaType:    mediaType,
+		artifactType: fields.ArtifactType,
+	}
+	if m.artifactType == "" && fields.Config != nil {
+		m.artifactType = fields.Config.MediaType
+	}
+
+	if s.manifests[name] == nil {
+		s.manifests[name] = make(map[digest.Digest]manifest)
+		s.tags[name] = make(map[string]digest.Digest)
+		s.referrers[name] = make(map[digest.Digest][]digest.Digest)
+	}
+	if _, exists := s.manifests[name][d]; !exists {
+		subject := fields.Subject
+		if subject == nil {
+			subject = fields.SubjectManifest
+		}
+		if subject != nil {
+			s.referrers[name][subject.Digest] = append(s.referrers[name][subject.Digest], d)
+		}
+	}
+	s.manifests[name][d] = m
+	if reference != d.String() {
+		s.tags[name][reference] = d
+	}
+}
+
+func (s *Server) serveReferrers(w http.ResponseWriter, r *http.Request, name, subject string) {
+	artifactType := r.URL.Query().Get("artifactType")
+	index := oci.Index{
+		Manifests: []oci.Descriptor{},
+	}
+	index.SchemaVersion = 2
+	for _, d := range s.referrers[name][digest.Digest(subject)] {
+		m := s.manifests[name][d]
+		if artifactType != "" && m.artifactType != artifactType {
+			continue
+		}
+		index.Manifests = append(index.Manifests, oci.Descriptor{
+			MediaType: m.mediaType,
+			Digest:    d,
+			Size:      int64(len(m.content)),
+		})
+	}
+	w.Header().Set("Content-Type", oci.MediaTypeImageIndex)
+	json.NewEncoder(w).Encode(index)
+}
+
+func (s *Server) serveExtReferrers(w http.ResponseWriter, r *http.Request, path string) {
+	i := strings.LastIndex(path, "/manifests/")
+	if !s.extAPI || i < 0 || !strings.HasSuffix(path, "/referrers") {
+		http.NotFound(w, r)
+		return
+	}
+	name := path[:i]
+	subject := strings.TrimSuffix(path[i+len("/manifests/"):], "/referrers")
+	referenceType := r.URL.Query().Get("referenceType")
+
+	type reference struct {
+		Digest   digest.Digest   `json:"digest"`
+		Manifest json.RawMessage `json:"manifest"`
+	}
+	result := struct {
+		References []reference `json:"references"`
+	}{
+		References: []reference{},
+	}
+	for _, d := range s.referrers[name][digest.Digest(subject)] {
+		m := s.manifests[name][d]
+		if m.mediaType != artifactspec.MediaTypeArtifactManifest {
+			continue
+		}
+		if referenceType != "" && m.artifactType != referenceType {
+			continue
+		}
+		result.References = append(result.References, reference{
+			Digest:   d,
+			Manifest: m.content,
+		})
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+func removeDigest(digests []digest.Digest, d digest.Digest) []digest.Digest {
+	result := digests[:0]
+	for _, existing := range digests {
+		if existing != d {
+			result = append(result, existing)
+		}
+	}
+	return result
+}
@@ -4,30 +4,463 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/url"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 
+	canonicaljson "github.com/docker/go/canonical/json"
+	"github.com/notaryproject/notary/v2"
 	artifactspecs "github.com/opencontainers/artifacts/specs-go"
 	artifactspec "github.com/opencontainers/artifacts/specs-go/v2"
 	"github.com/opencontainers/go-digest"
 	oci "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
-type repository struct {
-	tr   http.RoundTripper
-	base string
-	name string
+// Repository is a client to a repository in the remote registry
+// for accessing the signatures.
+type Repository struct {
+	// capabilities is accessed atomically, and comes first to be 64-bit
+	// aligned on 32-bit platforms
+	capabilities uint64
+
+	tr              http.RoundTripper
+	presignedTr     http.RoundTripper
+	base            string
+	name            string
+	referrersAPI    bool
+	successStatuses []int
+	linkConcurrency int
+	stats           *stats
+}
+
+// Name returns the name of the repository
+func (r *Repository) Name() string {
+	return r.name
+}
+
+// FullName returns the name of the repository prefixed by the registry host,
+// e.g. registry.example.com/teamA/app
+func (r *Repository) FullName() string {
+	host := strings.TrimSuffix(r.base, "/v2")
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+len("://"):]
+	}
+	return host + "/" + r.name
+}
+
+// Base returns the base URL of the registry API, e.g. https://registry.example.com/v2
+func (r *Repository) Base() string {
+	return r.base
+}
+
+// Stats returns a snapshot of the operation counters of the repository
+func (r *Repository) Stats() RepositoryStats {
+	return r.stats.snapshot()
+}
+
+// ResetStats resets the operation counters of the repository
+func (r *Repository) ResetStats() {
+	r.stats.reset()
+}
+
+// Ping checks that the registry is reachable and that the credentials of the
+// transport are accepted. Authentication is left to the transport, which is
+// expected to cache the obtained token for the subsequent requests.
+// On success, the capabilities of the registry are detected and made
+// available by Capabilities.
+func (r *Repository) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.base+"/", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.tr.RoundTrip(req)
+	if err != nil {
+		return &notary.NotaryError{
+			Code:  notary.ErrCodeNetwork,
+			Op:    "ping",
+			Cause: err,
+		}
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		atomic.StoreUint64(&r.capabilities, uint64(r.detectCapabilities(ctx)))
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &notary.NotaryError{
+			Code:  notary.ErrCodeUnauthorized,
+			Op:    "ping",
+			Cause: fmt.Errorf("registry responded: %s", resp.Status),
+		}
+	default:
+		return statusError("ping registry", resp)
+	}
 }
 
-func (r *repository) Lookup(ctx context.Context, manifestDigest digest.Digest) ([]digest.Digest, error) {
+func (r *Repository) Lookup(ctx context.Context, manifestDigest digest.Digest, opts ...notary.LookupOption) ([]notary.SignatureRef, error) {
+	return r.lookup(ctx, manifestDigest, opts...)
+}
+
+func (r *Repository) LookupSet(ctx context.Context, manifestDigest digest.Digest, opts ...notary.LookupOption) (*notary.SignatureSet, error) {
+	refs, err := r.lookup(ctx, manifestDigest, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return notary.NewSignatureSet(refs...), nil
+}
+
+// LookupManifests finds all signature artifact manifests for the specified
+// manifest, for callers that need more than the signature blobs, e.g. the
+// artifact annotations.
+func (r *Repository) LookupManifests(ctx context.Context, manifestDigest digest.Digest) ([]artifactspec.Artifact, error) {
+	return r.ListReferrers(ctx, manifestDigest, ArtifactTypeNotaryV2)
+}
+
+// ListReferrers finds all artifact manifests of the artifact type referring to
+// the subject manifest.
+func (r *Repository) ListReferrers(ctx context.Context, subject digest.Digest, artifactType string) ([]artifactspec.Artifact, error) {
+	referrers, err := r.referrers(ctx, subject, artifactType)
+	if err != nil {
+		return nil, err
+	}
+	artifacts := make([]artifactspec.Artifact, 0, len(referrers))
+	for _, referrer := range referrers {
+		artifacts = append(artifacts, referrer.artifact)
+	}
+	return artifacts, nil
+}
+
+// GetArtifactManifest fetches the artifact manifest by its own digest, e.g. the
+// digest returned by Link. On registries supporting the referrers API, image
+// manifests linked with notary.WithImageManifestCompat are accepted as well.
+// A manifest served with another media type is rejected with an
+// UnexpectedMediaTypeError.
+func (r *Repository) GetArtifactManifest(ctx context.Context, artifactDigest digest.Digest) (artifactspec.Artifact, error) {
+	artifact, err := r.getArtifactManifest(ctx, artifactDigest)
+	count(&r.stats.GetTotal, &r.stats.GetErrors, err)
+	return artifact, err
+}
+
+// getArtifactManifest fetches the artifact manifest without counting the
+// request, for the operations counted on their own.
+func (r *Repository) getArtifactManifest(ctx context.Context, artifactDigest digest.Digest) (artifactspec.Artifact, error) {
+	accepted := []string{artifactspec.MediaTypeArtifactManifest}
+	if r.referrersAPI {
+		accepted = []string{MediaTypeOCIArtifactManifest, oci.MediaTypeImageManifest}
+	}
+	manifestJSON, contentType, err := r.getManifest(ctx, artifactDigest, strings.Join(accepted, ", "))
+	if err != nil {
+		return artifactspec.Artifact{}, err
+	}
+	atomic.AddInt64(&r.stats.BytesDownloaded, int64(len(manifestJSON)))
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !containsString(accepted, mediaType) {
+		return artifactspec.Artifact{}, &UnexpectedMediaTypeError{
+			NotaryError: notary.NotaryError{
+				Code: notary.ErrCodeInvalidFormat,
+				Op:   "get artifact manifest",
+			},
+			MediaType: contentType,
+			Accepted:  accepted,
+		}
+	}
+	return ParseArtifactManifest(manifestJSON, mediaType)
+}
+
+// peekSize is the number of leading bytes of an artifact manifest fetched by
+// PeekArtifactType. The artifactType field sorts right after the annotations
+// in the canonical JSON, so it is covered unless the annotations are long.
+const peekSize = 256
+
+// artifactTypeRegexp extracts the artifact type from a partial manifest
+var artifactTypeRegexp = regexp.MustCompile(`"artifactType"\s*:\s*"([^"]*)"`)
+
+// PeekArtifactType returns the artifact type of the artifact manifest without
+// downloading the whole manifest where possible. The type is taken from the
+// artifactType parameter of the Content-Type header if the registry includes
+// it, or else from the leading bytes of the manifest. Image manifests, and
+// artifact manifests whose artifactType field is beyond the leading bytes,
+// are downloaded and decoded in full.
+func (r *Repository) PeekArtifactType(ctx context.Context, artifactDigest digest.Digest) (artifactType string, err error) {
+	defer func() {
+		count(&r.stats.GetTotal, &r.stats.GetErrors, err)
+	}()
+	accept := artifactspec.MediaTypeArtifactManifest
+	if r.referrersAPI {
+		accept = MediaTypeOCIArtifactManifest + ", " + oci.MediaTypeImageManifest
+	}
+	url := fmt.Sprintf("%s/%s/manifests/%s", r.base, r.name, artifactDigest.String())
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", accept)
+	resp, err := r.tr.RoundTrip(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", statusError("peek manifest", resp)
+	}
+	if mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+		if artifactType := params["artifacttype"]; artifactType != "" {
+			return artifactType, nil
+		}
+		if mediaType == oci.MediaTypeImageManifest {
+			// the artifact type is the media type of the config
+			return r.fetchArtifactType(ctx, artifactDigest)
+		}
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", peekSize-1))
+	resp, err = r.tr.RoundTrip(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return "", statusError("peek manifest", resp)
+	}
+	// registries may ignore the range and serve the whole manifest
+	head, err := io.ReadAll(io.LimitReader(resp.Body, peekSize))
+	if err != nil {
+		return "", err
+	}
+	atomic.AddInt64(&r.stats.BytesDownloaded, int64(len(head)))
+	if match := artifactTypeRegexp.FindSubmatch(head); match != nil {
+		return string(match[1]), nil
+	}
+	return r.fetchArtifactType(ctx, artifactDigest)
+}
+
+// fetchArtifactType downloads and decodes the whole artifact manifest to find
+// its artifact type.
+func (r *Repository) fetchArtifactType(ctx context.Context, artifactDigest digest.Digest) (string, error) {
+	artifact, err := r.getArtifactManifest(ctx, artifactDigest)
+	if err != nil {
+		return "", err
+	}
+	return artifact.ArtifactType, nil
+}
+
+// lookup finds the signatures for the manifest. No registry filters the
+// referrers by annotations, so the annotation filters are applied on the
+// artifact manifests in the referrers response.
+func (r *Repository) lookup(ctx context.Context, manifestDigest digest.Digest, opts ...notary.LookupOption) ([]notary.SignatureRef, error) {
+	options := notary.NewLookupOptions(opts...)
+	referrers, err := r.referrers(ctx, manifestDigest, ArtifactTypeNotaryV2)
+	if err != nil {
+		return nil, err
+	}
+	var refs []notary.SignatureRef
+	for _, referrer := range referrers {
+		if !options.MatchAnnotations(referrer.artifact.Annotations) {
+			continue
+		}
+		refs = append(refs, referrer.signatureRefs()...)
+	}
+	return refs, nil
+}
+
+// MultiTypeSignatureRef references a signature found under one or more
+// artifact types
+type MultiTypeSignatureRef struct {
+	notary.SignatureRef
+
+	// ArtifactTypes are the artifact types the signature is found under
+	ArtifactTypes []string
+}
+
+// LookupMultiType finds the signatures for the manifest under any of the
+// artifact types, querying the artifact types concurrently. Signatures found
+// under multiple artifact types are merged by their blob digest.
+func (r *Repository) LookupMultiType(ctx context.Context, subject digest.Digest, artifactTypes []string) ([]MultiTypeSignatureRef, error) {
+	results := make([][]referrer, len(artifactTypes))
+	errs := make([]error, len(artifactTypes))
+	var wg sync.WaitGroup
+	for i, artifactType := range artifactTypes {
+		wg.Add(1)
+		go func(i int, artifactType string) {
+			defer wg.Done()
+			results[i], errs[i] = r.referrers(ctx, subject, artifactType)
+		}(i, artifactType)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var refs []MultiTypeSignatureRef
+	index := make(map[digest.Digest]int)
+	for i, referrers := range results {
+		for _, referrer := range referrers {
+			for _, ref := range referrer.signatureRefs() {
+				blobDigest := ref.SignatureBlobDescriptor.Digest
+				j, found := index[blobDigest]
+				if !found {
+					j = len(refs)
+					index[blobDigest] = j
+					refs = append(refs, MultiTypeSignatureRef{
+						SignatureRef: ref,
+					})
+				}
+				if !containsString(refs[j].ArtifactTypes, artifactTypes[i]) {
+					refs[j].ArtifactTypes = append(refs[j].ArtifactTypes, artifactTypes[i])
+				}
+			}
+		}
+	}
+	return refs, nil
+}
+
+// referrer is an artifact manifest referring to a manifest
+type referrer struct {
+	desc     oci.Descriptor
+	artifact artifactspec.Artifact
+}
+
+// signatureRefs references the signature blobs of the artifact
+func (r referrer) signatureRefs() []notary.SignatureRef {
+	refs := make([]notary.SignatureRef, 0, len(r.artifact.Blobs))
+	for _, blob := range r.artifact.Blobs {
+		refs = append(refs, notary.SignatureRef{
+			ArtifactDescriptor:      r.desc,
+			SignatureBlobDescriptor: ociDescriptorFromArtifact(blob),
+			SubjectDescriptor:       ociDescriptorFromArtifact(r.artifact.SubjectManifest),
+			Platform:                notary.PlatformFromAnnotations(r.artifact.Annotations),
+		})
+	}
+	return refs
+}
+
+// marshalArtifact encodes the artifact manifest in the format supported by
+// the registry.
+func (r *Repository) marshalArtifact(artifact artifactspec.Artifact) ([]byte, error) {
+	if r.referrersAPI {
+		return canonicaljson.MarshalCanonical(newOCIArtifact(artifact))
+	}
+	return MarshalArtifactJSON(artifact)
+}
+
+// marshalImageArtifact pushes the synthetic config and encodes the artifact
+// as an image manifest.
+func (r *Repository) marshalImageArtifact(ctx context.Context, artifact artifactspec.Artifact) ([]byte, error) {
+	if err := r.putBlob(ctx, imageArtifactConfig, digest.FromBytes(imageArtifactConfig)); err != nil {
+		return nil, err
+	}
+	return canonicaljson.MarshalCanonical(newOCIImageArtifact(artifact))
+}
+
+func (r *Repository) referrers(ctx context.Context, manifestDigest digest.Digest, artifactType string) (referrers []referrer, err error) {
+	defer func() {
+		count(&r.stats.LookupTotal, &r.stats.LookupErrors, err)
+	}()
+
+	if r.referrersAPI {
+		return r.ociReferrers(ctx, manifestDigest, artifactType)
+	}
+	return r.extReferrers(ctx, manifestDigest, artifactType)
+}
+
+// ociReferrers finds the referrers with the referrers API of the OCI
+// distribution spec v1.1, and fetches each artifact manifest.
+func (r *Repository) ociReferrers(ctx context.Context, manifestDigest digest.Digest, artifactType string) ([]referrer, error) {
+	url, err := url.Parse(fmt.Sprintf("%s/%s/referrers/%s", r.base, r.name, manifestDigest.String()))
+	if err != nil {
+		return nil, err
+	}
+	q := url.Query()
+	q.Add("artifactType", artifactType)
+	url.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", oci.MediaTypeImageIndex)
+	resp, err := r.tr.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError("lookup signatures", resp)
+	}
+
+	var index oci.Index
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxReadLimit)).Decode(&index); err != nil {
+		return nil, err
+	}
+	var referrers []referrer
+	seen := make(map[digest.Digest]bool, len(index.Manifests))
+	for _, desc := range index.Manifests {
+		if desc.MediaType != MediaTypeOCIArtifactManifest && desc.MediaType != oci.MediaTypeImageManifest {
+			continue
+		}
+		// registries may list a referrer more than once, e.g. when it is
+		// indexed by both the referrers API and the tag schema
+		if seen[desc.Digest] {
+			continue
+		}
+		seen[desc.Digest] = true
+		manifestJSON, _, err := r.getManifest(ctx, desc.Digest, desc.MediaType)
+		if err != nil {
+			return nil, err
+		}
+		artifact, err := parseOCIReferrer(manifestJSON, desc.MediaType)
+		if err != nil {
+			return nil, err
+		}
+		if artifact.ArtifactType != artifactType {
+			continue
+		}
+		if artifact.SubjectManifest.Digest != manifestDigest {
+			return nil, &ManifestIntegrityError{
+				NotaryError: notary.NotaryError{
+					Code: notary.ErrCodeDigestMismatch,
+					Op:   "lookup",
+				},
+				Expected: manifestDigest,
+				Actual:   artifact.SubjectManifest.Digest,
+			}
+		}
+		artifactDesc := DescriptorFromBytes(manifestJSON)
+		artifactDesc.MediaType = desc.MediaType
+		artifactDesc.Annotations = artifact.Annotations
+		referrers = append(referrers, referrer{
+			desc:     artifactDesc,
+			artifact: artifact,
+		})
+	}
+	return referrers, nil
+}
+
+// extReferrers finds the referrers with the artifacts extension API.
+func (r *Repository) extReferrers(ctx context.Context, manifestDigest digest.Digest, artifactType string) ([]referrer, error) {
 	url, err := url.Parse(fmt.Sprintf("%s/_ext/oci-artifacts/v1-rc1/%s/manifests/%s/referrers", r.base, r.name, manifestDigest.String()))
 	if err != nil {
 		return nil, err
 	}
 	q := url.Query()
-	q.Add("referenceType", ArtifactTypeNotaryV2)
+	q.Add("referenceType", artifactType)
 	url.RawQuery = q.Encode()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
@@ -40,37 +473,260 @@ func (r *repository) Lookup(ctx context.Context, manifestDigest digest.Digest) (
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to lookup signatures: %s", resp.Status)
+		return nil, statusError("lookup signatures", resp)
 	}
 
 	result := struct {
 		References []struct {
-			Manifest artifactspec.Artifact `json:"manifest"`
+			Digest   digest.Digest   `json:"digest"`
+			Manifest json.RawMessage `json:"manifest"`
 		} `json:"references"`
 	}{}
 	if err := json.NewDecoder(io.LimitReader(resp.Body, maxReadLimit)).Decode(&result); err != nil {
 		return nil, err
 	}
-	digests := make([]digest.Digest, 0, len(result.References))
-	for _, artifact := range result.References {
-		for _, blob := range artifact.Manifest.Blobs {
-			digests = append(digests, blob.Digest)
+	var referrers []referrer
+	seen := make(map[digest.Digest]bool, len(result.References))
+	for _, reference := range result.References {
+		var artifact artifactspec.Artifact
+		if err := UnmarshalArtifactJSON(reference.Manifest, &artifact); err != nil {
+			return nil, err
+		}
+
+		// The registry must not be trusted to serve the artifacts as is.
+		// Check that each artifact links to the requested manifest and
+		// matches the digest it is referred by.
+		if artifact.SubjectManifest.Digest != manifestDigest {
+			return nil, &ManifestIntegrityError{
+				NotaryError: notary.NotaryError{
+					Code: notary.ErrCodeDigestMismatch,
+					Op:   "lookup",
+				},
+				Expected: manifestDigest,
+				Actual:   artifact.SubjectManifest.Digest,
+			}
+		}
+		artifactDesc := DescriptorFromBytes(reference.Manifest)
+		if reference.Digest != "" && reference.Digest != artifactDesc.Digest {
+			return nil, &ManifestIntegrityError{
+				NotaryError: notary.NotaryError{
+					Code: notary.ErrCodeDigestMismatch,
+					Op:   "lookup",
+				},
+				Expected: reference.Digest,
+				Actual:   artifactDesc.Digest,
+			}
 		}
+		if seen[artifactDesc.Digest] {
+			continue
+		}
+		seen[artifactDesc.Digest] = true
+		artifactDesc.MediaType = artifact.MediaType
+		artifactDesc.Annotations = artifact.Annotations
+		referrers = append(referrers, referrer{
+			desc:     artifactDesc,
+			artifact: artifact,
+		})
 	}
-	return digests, nil
+	return referrers, nil
 }
 
-func (r *repository) Get(ctx context.Context, signatureDigest digest.Digest) ([]byte, error) {
-	return r.getBlob(ctx, signatureDigest)
+func (r *Repository) Get(ctx context.Context, signatureDigest digest.Digest) ([]byte, error) {
+	signature, err := r.getBlob(ctx, signatureDigest)
+	count(&r.stats.GetTotal, &r.stats.GetErrors, err)
+	if err != nil {
+		return nil, err
+	}
+	atomic.AddInt64(&r.stats.BytesDownloaded, int64(len(signature)))
+	return signature, nil
 }
 
-func (r *repository) Put(ctx context.Context, signature []byte) (oci.Descriptor, error) {
+func (r *Repository) Put(ctx context.Context, signature []byte) (oci.Descriptor, error) {
+	if _, err := r.PutBlob(ctx, signature); err != nil {
+		return oci.Descriptor{}, err
+	}
 	desc := DescriptorFromBytes(signature)
 	desc.MediaType = MediaTypeNotarySignature
-	return desc, r.putBlob(ctx, signature, desc.Digest)
+	return desc, nil
+}
+
+// GetBlob fetches the blob of any media type by its digest
+func (r *Repository) GetBlob(ctx context.Context, d digest.Digest) ([]byte, error) {
+	return r.Get(ctx, d)
+}
+
+// GetBlobRange fetches the bytes from start to end inclusive of the blob, so
+// that large blobs can be processed in chunks. If the registry ignores the
+// range and serves the whole blob, the bytes before start are skipped. The
+// content is not verified against the digest, which covers the whole blob
+// only.
+func (r *Repository) GetBlobRange(ctx context.Context, d digest.Digest, start, end int64) (content []byte, err error) {
+	defer func() {
+		count(&r.stats.GetTotal, &r.stats.GetErrors, err)
+	}()
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("invalid range: %d-%d", start, end)
+	}
+	url := fmt.Sprintf("%s/%s/blobs/%s", r.base, r.name, d.String())
+	rangeHeader := fmt.Sprintf("bytes=%d-%d", start, end)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", rangeHeader)
+	resp, err := r.tr.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTemporaryRedirect {
+		resp.Body.Close()
+		location, err := resp.Location()
+		if err != nil {
+			return nil, err
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, location.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Range", rangeHeader)
+		if isPresignedURL(location) {
+			resp, err = r.roundTripPresigned(req)
+		} else {
+			resp, err = r.tr.RoundTrip(req)
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+	}
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		var gotStart, gotEnd int64
+		if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-%d/", &gotStart, &gotEnd); err != nil || gotStart != start || gotEnd != end {
+			return nil, fmt.Errorf("mismatch content range: expect %d-%d: got %q", start, end, resp.Header.Get("Content-Range"))
+		}
+	case http.StatusOK:
+		// a blob shorter than start is reported as short content below
+		if _, err := io.CopyN(io.Discard, resp.Body, start); err != nil && err != io.EOF {
+			return nil, err
+		}
+	default:
+		return nil, statusError("get blob range", resp)
+	}
+	size := end - start + 1
+	content, err = io.ReadAll(io.LimitReader(resp.Body, size))
+	if err != nil {
+		return nil, err
+	}
+	atomic.AddInt64(&r.stats.BytesDownloaded, int64(len(content)))
+	if int64(len(content)) != size {
+		return nil, fmt.Errorf("short content: expect %d bytes: got %d", size, len(content))
+	}
+	return content, nil
 }
 
-func (r *repository) Link(ctx context.Context, manifest, signature oci.Descriptor) (oci.Descriptor, error) {
+// PutBlob uploads the blob of any media type, e.g. the content of an artifact
+// to be signed.
+func (r *Repository) PutBlob(ctx context.Context, data []byte) (digest.Digest, error) {
+	d := digest.FromBytes(data)
+	err := r.putBlob(ctx, data, d)
+	count(&r.stats.PutTotal, &r.stats.PutErrors, err)
+	if err != nil {
+		return "", err
+	}
+	atomic.AddInt64(&r.stats.BytesUploaded, int64(len(data)))
+	return d, nil
+}
+
+// PutAll uploads the signatures concurrently, e.g. the signatures of a
+// MultiKeySigner, and returns their descriptors in the order of the signatures.
+func (r *Repository) PutAll(ctx context.Context, signatures [][]byte) ([]oci.Descriptor, error) {
+	descs := make([]oci.Descriptor, len(signatures))
+	errs := make([]error, len(signatures))
+	var wg sync.WaitGroup
+	for i, signature := range signatures {
+		wg.Add(1)
+		go func(i int, signature []byte) {
+			defer wg.Done()
+			descs[i], errs[i] = r.Put(ctx, signature)
+		}(i, signature)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return descs, nil
+}
+
+// BatchLink links the signatures to the same manifest concurrently, e.g. the
+// signatures of multiple signers, and returns the artifact descriptors in the
+// order of the signatures. The links are not atomic: if some of them fail,
+// the others are still pushed and returned with a PartialResultError keyed
+// by the signature digests.
+func (r *Repository) BatchLink(ctx context.Context, manifest oci.Descriptor, signatures []oci.Descriptor, opts ...notary.LinkOption) ([]oci.Descriptor, error) {
+	concurrency := r.linkConcurrency
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+	if concurrency > len(signatures) {
+		concurrency = len(signatures)
+	}
+
+	descs := make([]oci.Descriptor, len(signatures))
+	errs := make([]error, len(signatures))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				descs[i], errs[i] = r.Link(ctx, manifest, signatures[i], opts...)
+			}
+		}()
+	}
+	for i := range signatures {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	failed := make(map[digest.Digest]error)
+	for i, err := range errs {
+		if err != nil {
+			failed[signatures[i].Digest] = err
+		}
+	}
+	if len(failed) > 0 {
+		return descs, &notary.PartialResultError{
+			NotaryError: notary.NotaryError{
+				Code: notary.ErrCodePartialResult,
+				Op:   "batch link",
+			},
+			Errors: failed,
+		}
+	}
+	return descs, nil
+}
+
+func (r *Repository) Link(ctx context.Context, manifest, signature oci.Descriptor, opts ...notary.LinkOption) (desc oci.Descriptor, err error) {
+	defer func() {
+		count(&r.stats.LinkTotal, &r.stats.LinkErrors, err)
+	}()
+
+	options := notary.NewLinkOptions(opts...)
+	if options.ImageManifestCompat && !r.referrersAPI {
+		// image manifests are only discoverable through the referrers API
+		return oci.Descriptor{}, &notary.NotaryError{
+			Code:  notary.ErrCodeInvalidFormat,
+			Op:    "link",
+			Cause: errors.New("image manifest compat requires the referrers API"),
+		}
+	}
 	artifact := artifactspec.Artifact{
 		Versioned: artifactspecs.Versioned{
 			SchemaVersion: 3,
@@ -81,16 +737,248 @@ func (r *repository) Link(ctx context.Context, manifest, signature oci.Descripto
 			artifactDescriptorFromOCI(signature),
 		},
 		SubjectManifest: artifactDescriptorFromOCI(manifest),
+		Annotations:     options.Annotations,
+	}
+	if err := ValidateArtifact(artifact); err != nil {
+		return oci.Descriptor{}, err
+	}
+	var artifactJSON []byte
+	if options.ImageManifestCompat {
+		artifactJSON, err = r.marshalImageArtifact(ctx, artifact)
+	} else {
+		artifactJSON, err = r.marshalArtifact(artifact)
+	}
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+	desc, err = DescriptorFromManifest(artifactJSON)
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+	if err := r.putManifest(ctx, artifactJSON, desc.MediaType, desc.Digest.String()); err != nil {
+		return oci.Descriptor{}, err
+	}
+	atomic.AddInt64(&r.stats.BytesUploaded, desc.Size)
+	return desc, nil
+}
+
+// LinkPlatform links the signature to the manifest of the given platform,
+// which is recorded in the artifact manifest and returned by Lookup.
+func (r *Repository) LinkPlatform(ctx context.Context, manifest oci.Descriptor, platform oci.Platform, signature oci.Descriptor) (oci.Descriptor, error) {
+	if platform.OS == "" || platform.Architecture == "" {
+		return oci.Descriptor{}, &notary.NotaryError{
+			Code:  notary.ErrCodeInvalidFormat,
+			Op:    "link",
+			Cause: errors.New("platform os and architecture are required"),
+		}
+	}
+	return r.Link(ctx, manifest, signature, notary.WithPlatform(platform))
+}
+
+// reservedAnnotationPrefixes are the prefixes of the artifact annotations
+// owned by notary, e.g. notary.AnnotationPlatformOS and
+// notary.AnnotationSigningMethod, which cannot be updated after linking.
+var reservedAnnotationPrefixes = []string{
+	"io.cncf.notary.",
+	"io.notary.",
+}
+
+// isReservedAnnotation reports whether the annotation is owned by notary
+func isReservedAnnotation(key string) bool {
+	for _, prefix := range reservedAnnotationPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateSignatureAnnotations updates the annotations of the artifact manifest
+// linking a signature, e.g. to replace an expired build URL. The annotations
+// are not covered by the signature, so the signature stays valid. An empty
+// value removes the annotation. The artifact is pushed as a new manifest, and
+// its descriptor is returned; the original artifact is left in place.
+func (r *Repository) UpdateSignatureAnnotations(ctx context.Context, artifactDigest digest.Digest, updates map[string]string) (desc oci.Descriptor, err error) {
+	defer func() {
+		count(&r.stats.PutTotal, &r.stats.PutErrors, err)
+	}()
+	for key := range updates {
+		if isReservedAnnotation(key) {
+			return oci.Descriptor{}, fmt.Errorf("reserved annotation cannot be updated: %s", key)
+		}
+	}
+	artifact, err := r.getArtifactManifest(ctx, artifactDigest)
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+
+	annotations := make(map[string]string, len(artifact.Annotations)+len(updates))
+	for key, value := range artifact.Annotations {
+		annotations[key] = value
+	}
+	for key, value := range updates {
+		if value == "" {
+			delete(annotations, key)
+			continue
+		}
+		annotations[key] = value
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	artifact.Annotations = annotations
+
+	var artifactJSON []byte
+	if artifact.MediaType == oci.MediaTypeImageManifest {
+		artifactJSON, err = r.marshalImageArtifact(ctx, artifact)
+	} else {
+		artifactJSON, err = r.marshalArtifact(artifact)
+	}
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+	desc, err = DescriptorFromManifest(artifactJSON)
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+	if err := r.putManifest(ctx, artifactJSON, desc.MediaType, desc.Digest.String()); err != nil {
+		return oci.Descriptor{}, err
+	}
+	atomic.AddInt64(&r.stats.BytesUploaded, desc.Size)
+	return desc, nil
+}
+
+// GetManifest fetches the manifest of any supported media type by its digest,
+// and returns it with its media type.
+func (r *Repository) GetManifest(ctx context.Context, d digest.Digest) ([]byte, string, error) {
+	manifest, mediaType, err := r.getManifest(ctx, d, strings.Join(manifestMediaTypes, ", "))
+	count(&r.stats.GetTotal, &r.stats.GetErrors, err)
+	if err != nil {
+		return nil, "", err
+	}
+	atomic.AddInt64(&r.stats.BytesDownloaded, int64(len(manifest)))
+	return manifest, mediaType, nil
+}
+
+// PutManifest uploads the manifest of the given media type
+func (r *Repository) PutManifest(ctx context.Context, manifest []byte, mediaType string) (digest.Digest, error) {
+	desc, err := r.PutTaggedManifest(ctx, manifest, mediaType, "")
+	if err != nil {
+		return "", err
+	}
+	return desc.Digest, nil
+}
+
+// PutTaggedManifest uploads the manifest of the given media type, and tags it
+// if tag is not empty.
+func (r *Repository) PutTaggedManifest(ctx context.Context, manifest []byte, mediaType, tag string) (oci.Descriptor, error) {
+	desc := DescriptorFromBytes(manifest)
+	desc.MediaType = mediaType
+	reference := tag
+	if reference == "" {
+		reference = desc.Digest.String()
+	}
+	err := r.putManifest(ctx, manifest, mediaType, reference)
+	count(&r.stats.PutTotal, &r.stats.PutErrors, err)
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+	atomic.AddInt64(&r.stats.BytesUploaded, desc.Size)
+	return desc, nil
+}
+
+// DeleteManifest deletes the manifest by its digest, e.g. the artifact
+// linking a signature to a manifest, which is no longer found on lookup.
+func (r *Repository) DeleteManifest(ctx context.Context, d digest.Digest) error {
+	url := fmt.Sprintf("%s/%s/manifests/%s", r.base, r.name, d.String())
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.tr.RoundTrip(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		return statusError("delete manifest", resp)
+	}
+	return nil
+}
+
+// MountBlob mounts the blob from the source repository in the same registry,
+// avoiding the upload of its content. It returns false if the registry does
+// not mount the blob, in which case the blob must be uploaded.
+func (r *Repository) MountBlob(ctx context.Context, d digest.Digest, sourceRepo string) (bool, error) {
+	url, err := url.Parse(fmt.Sprintf("%s/%s/blobs/uploads/", r.base, r.name))
+	if err != nil {
+		return false, err
+	}
+	q := url.Query()
+	q.Add("mount", d.String())
+	q.Add("from", sourceRepo)
+	url.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url.String(), nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := r.tr.RoundTrip(req)
+	if err != nil {
+		return false, err
 	}
-	artifactJSON, err := json.Marshal(artifact)
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusCreated:
+		atomic.AddUint64(&r.stats.CacheHits, 1)
+		return true, nil
+	case http.StatusAccepted:
+		atomic.AddUint64(&r.stats.CacheMisses, 1)
+		return false, nil
+	default:
+		return false, statusError("mount blob", resp)
+	}
+}
+
+// Resolve resolves a tag or a digest to the descriptor of the manifest
+func (r *Repository) Resolve(ctx context.Context, reference string) (oci.Descriptor, error) {
+	url := fmt.Sprintf("%s/%s/manifests/%s", r.base, r.name, reference)
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
 	if err != nil {
 		return oci.Descriptor{}, err
 	}
-	desc := DescriptorFromBytes(artifactJSON)
-	return desc, r.putManifest(ctx, artifactJSON, desc.Digest)
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	resp, err := r.tr.RoundTrip(req)
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return oci.Descriptor{}, statusError("resolve "+reference, resp)
+	}
+
+	manifestDigest, err := digest.Parse(resp.Header.Get("Docker-Content-Digest"))
+	if err != nil {
+		return oci.Descriptor{}, fmt.Errorf("failed to resolve %s: %v", reference, err)
+	}
+	if expected, err := digest.Parse(reference); err == nil && expected != manifestDigest {
+		return oci.Descriptor{}, &ManifestIntegrityError{
+			NotaryError: notary.NotaryError{
+				Code: notary.ErrCodeDigestMismatch,
+				Op:   "resolve",
+			},
+			Expected: expected,
+			Actual:   manifestDigest,
+		}
+	}
+	return oci.Descriptor{
+		MediaType: resp.Header.Get("Content-Type"),
+		Digest:    manifestDigest,
+		Size:      resp.ContentLength,
+	}, nil
 }
 
-func (r *repository) getBlob(ctx context.Context, digest digest.Digest) ([]byte, error) {
+func (r *Repository) getBlob(ctx context.Context, digest digest.Digest) ([]byte, error) {
 	url := fmt.Sprintf("%s/%s/blobs/%s", r.base, r.name, digest.String())
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -105,7 +993,7 @@ func (r *repository) getBlob(ctx context.Context, digest digest.Digest) ([]byte,
 		return readAllVerified(resp.Body, digest)
 	}
 	if resp.StatusCode != http.StatusTemporaryRedirect {
-		return nil, fmt.Errorf("failed to get blob: %s", resp.Status)
+		return nil, statusError("get blob", resp)
 	}
 	resp.Body.Close()
 
@@ -113,22 +1001,73 @@ func (r *repository) getBlob(ctx context.Context, digest digest.Digest) ([]byte,
 	if err != nil {
 		return nil, err
 	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
 	req, err = http.NewRequestWithContext(ctx, http.MethodGet, location.String(), nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err = r.tr.RoundTrip(req)
+	if isPresignedURL(location) {
+		resp, err = r.roundTripPresigned(req)
+	} else {
+		resp, err = r.tr.RoundTrip(req)
+	}
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get blob: %s", resp.Status)
+		return nil, statusError("get blob", resp)
 	}
 	return readAllVerified(resp.Body, digest)
 }
 
-func (r *repository) putBlob(ctx context.Context, blob []byte, digest digest.Digest) error {
+// PutStreamBlobWithLength uploads the blob of the given size and digest read
+// from rd, without loading it into memory.
+func (r *Repository) PutStreamBlobWithLength(ctx context.Context, rd io.Reader, size int64, d digest.Digest) error {
+	err := r.putBlobStream(ctx, rd, size, d)
+	count(&r.stats.PutTotal, &r.stats.PutErrors, err)
+	if err != nil {
+		return err
+	}
+	atomic.AddInt64(&r.stats.BytesUploaded, size)
+	return nil
+}
+
+// PutStreamBlobUnknownSize uploads the blob read from rd, whose size is not
+// known upfront. Since some registries reject uploads in chunked transfer
+// encoding, the blob is spooled to a temporary file while computing its
+// digest, and uploaded with its size once fully read.
+func (r *Repository) PutStreamBlobUnknownSize(ctx context.Context, rd io.Reader) (digest.Digest, int64, error) {
+	file, err := ioutil.TempFile("", "notary-blob-")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	w := NewDescriptorWriter(file)
+	if _, err := io.Copy(w, rd); err != nil {
+		return "", 0, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", 0, err
+	}
+	desc := w.Descriptor("")
+	if err := r.PutStreamBlobWithLength(ctx, file, desc.Size, desc.Digest); err != nil {
+		return "", 0, err
+	}
+	return desc.Digest, desc.Size, nil
+}
+
+func (r *Repository) putBlob(ctx context.Context, blob []byte, digest digest.Digest) error {
+	return r.putBlobStream(ctx, bytes.NewReader(blob), int64(len(blob)), digest)
+}
+
+func (r *Repository) putBlobStream(ctx context.Context, blob io.Reader, size int64, digest digest.Digest) error {
 	url := fmt.Sprintf("%s/%s/blobs/uploads/", r.base, r.name)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
 	if err != nil {
@@ -138,9 +1077,9 @@ func (r *repository) putBlob(ctx context.Context, blob []byte, digest digest.Dig
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusAccepted {
-		return fmt.Errorf("failed to init upload: %s", resp.Status)
+		return responseError("init upload", resp)
 	}
 
 	url = resp.Header.Get("Location")
@@ -148,10 +1087,15 @@ func (r *repository) putBlob(ctx context.Context, blob []byte, digest digest.Dig
 		return http.ErrNoLocation
 	}
 
-	req, err = http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(blob))
+	if size == 0 {
+		// an empty body of unknown type would be sent chunked
+		blob = http.NoBody
+	}
+	req, err = http.NewRequestWithContext(ctx, http.MethodPut, url, blob)
 	if err != nil {
 		return err
 	}
+	req.ContentLength = size
 	req.Header.Set("Content-Type", "application/octet-stream")
 	q := req.URL.Query()
 	q.Add("digest", digest.String())
@@ -160,35 +1104,157 @@ func (r *repository) putBlob(ctx context.Context, blob []byte, digest digest.Dig
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("failed to upload: %s", resp.Status)
+		return responseError("upload", resp)
 	}
 	return nil
 }
 
-func (r *repository) putManifest(ctx context.Context, blob []byte, digest digest.Digest) error {
+// getManifest fetches the manifest accepting the given media types, and
+// returns it with the media type served by the registry.
+func (r *Repository) getManifest(ctx context.Context, digest digest.Digest, accept string) ([]byte, string, error) {
 	url := fmt.Sprintf("%s/%s/manifests/%s", r.base, r.name, digest.String())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", accept)
+	resp, err := r.tr.RoundTrip(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", statusError("get manifest", resp)
+	}
+	manifest, err := readAllVerified(resp.Body, digest)
+	if err != nil {
+		return nil, "", err
+	}
+	return manifest, resp.Header.Get("Content-Type"), nil
+}
+
+func (r *Repository) putManifest(ctx context.Context, blob []byte, mediaType string, reference string) error {
+	if err := checkManifest(blob); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/%s/manifests/%s", r.base, r.name, reference)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(blob))
 	if err != nil {
 		return err
 	}
-	req.Header.Set("Content-Type", artifactspec.MediaTypeArtifactManifest)
+	req.Header.Set("Content-Type", mediaType)
 	resp, err := r.tr.RoundTrip(req)
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
+	for _, status := range r.successStatuses {
+		if resp.StatusCode == status {
+			return nil
+		}
+	}
+	// Some registries, including the distribution spec reference server,
+	// reply 409 Conflict if the manifest already exists instead of
+	// accepting the identical push again. Such a push succeeded as long as
+	// the reference already resolves to the same content, which keeps
+	// retried Link calls idempotent. A conflict on a tag pointing to other
+	// content is a failure.
+	if resp.StatusCode == http.StatusConflict {
+		if resolved, err := r.resolvesTo(ctx, reference, digest.FromBytes(blob)); err != nil || resolved {
+			return err
+		}
+	}
+	return responseError("put manifest", resp)
+}
+
+// resolvesTo checks that the reference, a tag or a digest, resolves to the
+// manifest with the digest
+func (r *Repository) resolvesTo(ctx context.Context, reference string, d digest.Digest) (bool, error) {
+	url := fmt.Sprintf("%s/%s/manifests/%s", r.base, r.name, reference)
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	resp, err := r.tr.RoundTrip(req)
+	if err != nil {
+		return false, err
+	}
 	resp.Body.Close()
-	if resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("failed to put manifest: %s", resp.Status)
+	switch resp.StatusCode {
+	case http.StatusOK:
+		served := resp.Header.Get("Docker-Content-Digest")
+		if served == "" {
+			// only a digest reference is known to resolve to itself
+			return reference == d.String(), nil
+		}
+		return served == d.String(), nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, statusError("check manifest", resp)
+	}
+}
+
+// checkManifest rejects manifests which the registry is bound to reject, so
+// that the error is reported before sending the request.
+func checkManifest(blob []byte) error {
+	var cause error
+	if len(blob) > MaxManifestSize {
+		cause = fmt.Errorf("manifest size %d exceeds %d", len(blob), MaxManifestSize)
+	} else if trimmed := bytes.TrimLeft(blob, " \t\r\n"); len(trimmed) == 0 || trimmed[0] != '{' {
+		cause = errors.New("manifest is not a JSON object")
+	}
+	if cause == nil {
+		return nil
+	}
+	return &notary.NotaryError{
+		Code:  notary.ErrCodeInvalidFormat,
+		Op:    "put manifest",
+		Cause: cause,
+	}
+}
+
+// isPresignedURL reports whether the URL is an S3 presigned URL
+func isPresignedURL(u *url.URL) bool {
+	return u.Query().Get("X-Amz-Signature") != ""
+}
+
+// roundTripPresigned sends the request to a presigned URL with the configured
+// transport of the repository. Presigned URLs carry the credentials in the
+// query, and storage services like S3 reject requests with the registry
+// credentials in the Authorization header, so none is sent. Further redirects
+// are not followed, and fail on the status check of the caller.
+func (r *Repository) roundTripPresigned(req *http.Request) (*http.Response, error) {
+	req.Header.Del("Authorization")
+	return r.presignedTr.RoundTrip(req)
+}
+
+func ociDescriptorFromArtifact(desc artifactspec.Descriptor) oci.Descriptor {
+	return oci.Descriptor{
+		MediaType:   desc.MediaType,
+		Digest:      desc.Digest,
+		Size:        desc.Size,
+		Annotations: desc.Annotations,
 	}
-	return nil
 }
 
 func artifactDescriptorFromOCI(desc oci.Descriptor) artifactspec.Descriptor {
 	return artifactspec.Descriptor{
-		MediaType: desc.MediaType,
-		Digest:    desc.Digest,
-		Size:      desc.Size,
+		MediaType:   desc.MediaType,
+		Digest:      desc.Digest,
+		Size:        desc.Size,
+		Annotations: desc.Annotations,
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
 	}
+	return false
 }
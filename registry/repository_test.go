@@ -0,0 +1,940 @@
+package registry_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/registry"
+	"github.com/notaryproject/notary/v2/registry/registrytest"
+	artifactspecs "github.com/opencontainers/artifacts/specs-go"
+	artifactspec "github.com/opencontainers/artifacts/specs-go/v2"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+var testSubject = oci.Descriptor{
+	MediaType: oci.MediaTypeImageManifest,
+	Digest:    digest.FromString("subject"),
+	Size:      7,
+}
+
+// newTestRepository starts a fake registry and returns a repository on it
+func newTestRepository(t *testing.T, serverOpts []registrytest.Option, opts ...registry.RepositoryOption) (*registry.Repository, *registrytest.Server) {
+	t.Helper()
+	server := registrytest.NewServer(serverOpts...)
+	t.Cleanup(server.Close)
+	return registry.NewRepository(http.DefaultTransport, server.Host(), "test/app", true, opts...), server
+}
+
+// putTestSignature uploads an opaque signature blob
+func putTestSignature(t *testing.T, repo *registry.Repository, content string) oci.Descriptor {
+	t.Helper()
+	desc, err := repo.Put(context.Background(), []byte(content))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	return desc
+}
+
+func TestLinkImageManifestCompat(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newTestRepository(t, nil, registry.WithReferrersAPI())
+	sigDesc := putTestSignature(t, repo, "signature")
+
+	artifactDesc, err := repo.Link(ctx, testSubject, sigDesc, notary.WithImageManifestCompat())
+	if err != nil {
+		t.Fatalf("Link() error = %v", err)
+	}
+	if artifactDesc.MediaType != oci.MediaTypeImageManifest {
+		t.Fatalf("Link() media type = %q, want %q", artifactDesc.MediaType, oci.MediaTypeImageManifest)
+	}
+
+	refs, err := repo.Lookup(ctx, testSubject.Digest)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if len(refs) != 1 || refs[0].SignatureBlobDescriptor.Digest != sigDesc.Digest {
+		t.Fatalf("Lookup() = %v, want the signature %v", refs, sigDesc.Digest)
+	}
+
+	artifact, err := repo.GetArtifactManifest(ctx, artifactDesc.Digest)
+	if err != nil {
+		t.Fatalf("GetArtifactManifest() error = %v", err)
+	}
+	if artifact.ArtifactType != registry.ArtifactTypeNotaryV2 || artifact.SubjectManifest.Digest != testSubject.Digest {
+		t.Fatalf("GetArtifactManifest() = %+v, want a notary artifact of the subject", artifact)
+	}
+}
+
+func TestPeekArtifactType(t *testing.T) {
+	// the annotations sort before the artifact type and push it beyond the
+	// peeked bytes
+	longAnnotations := func(opts *notary.LinkOptions) {
+		opts.Annotations = map[string]string{
+			"org.example.description": strings.Repeat("x", 512),
+		}
+	}
+	for _, tt := range []struct {
+		name     string
+		repoOpts []registry.RepositoryOption
+		linkOpts []notary.LinkOption
+	}{
+		{
+			name: "artifacts extension",
+		},
+		{
+			name:     "artifacts extension with long annotations",
+			linkOpts: []notary.LinkOption{longAnnotations},
+		},
+		{
+			name:     "referrers API with long annotations",
+			repoOpts: []registry.RepositoryOption{registry.WithReferrersAPI()},
+			linkOpts: []notary.LinkOption{longAnnotations},
+		},
+		{
+			name:     "image manifest",
+			repoOpts: []registry.RepositoryOption{registry.WithReferrersAPI()},
+			linkOpts: []notary.LinkOption{notary.WithImageManifestCompat()},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo, _ := newTestRepository(t, nil, tt.repoOpts...)
+			sigDesc := putTestSignature(t, repo, "signature")
+			artifactDesc, err := repo.Link(ctx, testSubject, sigDesc, tt.linkOpts...)
+			if err != nil {
+				t.Fatalf("Link() error = %v", err)
+			}
+			got, err := repo.PeekArtifactType(ctx, artifactDesc.Digest)
+			if err != nil {
+				t.Fatalf("PeekArtifactType() error = %v", err)
+			}
+			if got != registry.ArtifactTypeNotaryV2 {
+				t.Fatalf("PeekArtifactType() = %q, want %q", got, registry.ArtifactTypeNotaryV2)
+			}
+		})
+	}
+}
+
+func TestPutTaggedManifestStatus(t *testing.T) {
+	manifest := []byte(`{"schemaVersion":2}`)
+	manifestDigest := digest.FromBytes(manifest)
+	for _, tt := range []struct {
+		name       string
+		tag        string
+		putStatus  int
+		headStatus int
+		headDigest digest.Digest
+		wantErr    bool
+	}{
+		{name: "created", tag: "v1", putStatus: http.StatusCreated},
+		{name: "accepted", tag: "v1", putStatus: http.StatusAccepted},
+		{name: "conflict on tag with the same manifest", tag: "v1", putStatus: http.StatusConflict, headStatus: http.StatusOK, headDigest: manifestDigest},
+		{name: "conflict on tag with another manifest", tag: "v1", putStatus: http.StatusConflict, headStatus: http.StatusOK, headDigest: digest.FromString("other"), wantErr: true},
+		{name: "conflict on tag without digest header", tag: "v1", putStatus: http.StatusConflict, headStatus: http.StatusOK, wantErr: true},
+		{name: "conflict on existing digest", putStatus: http.StatusConflict, headStatus: http.StatusOK},
+		{name: "conflict on missing manifest", tag: "v1", putStatus: http.StatusConflict, headStatus: http.StatusNotFound, wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			reference := tt.tag
+			if reference == "" {
+				reference = manifestDigest.String()
+			}
+			wantPath := "/v2/test/app/manifests/" + reference
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != wantPath {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				switch r.Method {
+				case http.MethodPut:
+					w.WriteHeader(tt.putStatus)
+				case http.MethodHead:
+					if tt.headDigest != "" {
+						w.Header().Set("Docker-Content-Digest", tt.headDigest.String())
+					}
+					w.WriteHeader(tt.headStatus)
+				default:
+					w.WriteHeader(http.StatusMethodNotAllowed)
+				}
+			}))
+			defer server.Close()
+			repo := registry.NewRepository(http.DefaultTransport, strings.TrimPrefix(server.URL, "http://"), "test/app", true)
+
+			_, err := repo.PutTaggedManifest(context.Background(), manifest, oci.MediaTypeImageManifest, tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PutTaggedManifest() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLinkImageManifestCompatWithoutReferrersAPI(t *testing.T) {
+	repo, _ := newTestRepository(t, nil)
+	sigDesc := putTestSignature(t, repo, "signature")
+
+	_, err := repo.Link(context.Background(), testSubject, sigDesc, notary.WithImageManifestCompat())
+	if !errors.Is(err, notary.ErrInvalidFormat) {
+		t.Fatalf("Link() error = %v, want %v", err, notary.ErrInvalidFormat)
+	}
+}
+
+func TestLookupImageManifestWithoutMediaType(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newTestRepository(t, nil, registry.WithReferrersAPI())
+	sigDesc := putTestSignature(t, repo, "signature")
+	config, err := repo.PutBlob(ctx, []byte("{}"))
+	if err != nil {
+		t.Fatalf("PutBlob() error = %v", err)
+	}
+
+	// the mediaType field is optional in image manifests
+	manifest, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"config": oci.Descriptor{
+			MediaType: registry.ArtifactTypeNotaryV2,
+			Digest:    config,
+			Size:      2,
+		},
+		"layers": []oci.Descriptor{{
+			MediaType: registry.MediaTypeNotarySignatureLayer,
+			Digest:    sigDesc.Digest,
+			Size:      sigDesc.Size,
+		}},
+		"subject": testSubject,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	artifactDigest, err := repo.PutManifest(ctx, manifest, oci.MediaTypeImageManifest)
+	if err != nil {
+		t.Fatalf("PutManifest() error = %v", err)
+	}
+
+	refs, err := repo.Lookup(ctx, testSubject.Digest)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if len(refs) != 1 || refs[0].ArtifactDescriptor.Digest != artifactDigest {
+		t.Fatalf("Lookup() = %v, want the artifact %v", refs, artifactDigest)
+	}
+	desc, err := repo.UpdateSignatureAnnotations(ctx, artifactDigest, map[string]string{"io.example.build.url": "https://ci.example.com/1"})
+	if err != nil {
+		t.Fatalf("UpdateSignatureAnnotations() error = %v", err)
+	}
+	if desc.MediaType != oci.MediaTypeImageManifest {
+		t.Errorf("UpdateSignatureAnnotations() media type = %q, want %q", desc.MediaType, oci.MediaTypeImageManifest)
+	}
+}
+
+func TestLookupDuplicateReferrers(t *testing.T) {
+	ctx := context.Background()
+	fake := registrytest.NewServer()
+	defer fake.Close()
+	// the registry lists every referrer twice
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Path, "/referrers/") {
+			fake.Config.Handler.ServeHTTP(w, r)
+			return
+		}
+		rec := httptest.NewRecorder()
+		fake.Config.Handler.ServeHTTP(rec, r)
+		var index oci.Index
+		if err := json.Unmarshal(rec.Body.Bytes(), &index); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		index.Manifests = append(index.Manifests, index.Manifests...)
+		w.Header().Set("Content-Type", oci.MediaTypeImageIndex)
+		json.NewEncoder(w).Encode(index)
+	}))
+	defer server.Close()
+	repo := registry.NewRepository(http.DefaultTransport, strings.TrimPrefix(server.URL, "http://"), "test/app", true, registry.WithReferrersAPI())
+	sigDesc := putTestSignature(t, repo, "signature")
+	if _, err := repo.Link(ctx, testSubject, sigDesc); err != nil {
+		t.Fatalf("Link() error = %v", err)
+	}
+
+	refs, err := repo.Lookup(ctx, testSubject.Digest)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if len(refs) != 1 {
+		t.Fatalf("Lookup() = %v, want a single signature", refs)
+	}
+}
+
+func TestGetArtifactManifestUnexpectedMediaType(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		mediaType string
+		opts      []registry.RepositoryOption
+	}{
+		{name: "image index", mediaType: oci.MediaTypeImageIndex, opts: []registry.RepositoryOption{registry.WithReferrersAPI()}},
+		{name: "image manifest without referrers API", mediaType: oci.MediaTypeImageManifest},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo, _ := newTestRepository(t, nil, tt.opts...)
+			d, err := repo.PutManifest(ctx, []byte(`{"schemaVersion":2,"mediaType":"`+tt.mediaType+`"}`), tt.mediaType)
+			if err != nil {
+				t.Fatalf("PutManifest() error = %v", err)
+			}
+
+			_, err = repo.GetArtifactManifest(ctx, d)
+			var mediaTypeErr *registry.UnexpectedMediaTypeError
+			if !errors.As(err, &mediaTypeErr) || mediaTypeErr.MediaType != tt.mediaType {
+				t.Fatalf("GetArtifactManifest() error = %v, want UnexpectedMediaTypeError of %s", err, tt.mediaType)
+			}
+			if !errors.Is(err, notary.ErrInvalidFormat) {
+				t.Errorf("GetArtifactManifest() error = %v, want %v", err, notary.ErrInvalidFormat)
+			}
+		})
+	}
+}
+
+func TestUpdateSignatureAnnotations(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newTestRepository(t, nil)
+	sigDesc := putTestSignature(t, repo, "signature")
+	platform := oci.Platform{OS: "linux", Architecture: "amd64"}
+	artifactDesc, err := repo.LinkPlatform(ctx, testSubject, platform, sigDesc)
+	if err != nil {
+		t.Fatalf("LinkPlatform() error = %v", err)
+	}
+
+	desc, err := repo.UpdateSignatureAnnotations(ctx, artifactDesc.Digest, map[string]string{"io.example.build.url": "https://ci.example.com/1"})
+	if err != nil {
+		t.Fatalf("UpdateSignatureAnnotations() error = %v", err)
+	}
+	if desc.Digest == artifactDesc.Digest {
+		t.Fatal("UpdateSignatureAnnotations() returned the original artifact")
+	}
+	artifact, err := repo.GetArtifactManifest(ctx, desc.Digest)
+	if err != nil {
+		t.Fatalf("GetArtifactManifest() error = %v", err)
+	}
+	want := map[string]string{
+		"io.example.build.url":                "https://ci.example.com/1",
+		notary.AnnotationPlatformOS:           "linux",
+		notary.AnnotationPlatformArchitecture: "amd64",
+	}
+	for key, value := range want {
+		if got := artifact.Annotations[key]; got != value {
+			t.Errorf("annotation %s = %q, want %q", key, got, value)
+		}
+	}
+	if len(artifact.Blobs) != 1 || artifact.Blobs[0].Digest != sigDesc.Digest {
+		t.Errorf("blobs = %v, want the signature %v", artifact.Blobs, sigDesc.Digest)
+	}
+	if artifact.SubjectManifest.Digest != testSubject.Digest {
+		t.Errorf("subject = %v, want %v", artifact.SubjectManifest.Digest, testSubject.Digest)
+	}
+
+	// an empty value removes the annotation
+	desc, err = repo.UpdateSignatureAnnotations(ctx, desc.Digest, map[string]string{"io.example.build.url": ""})
+	if err != nil {
+		t.Fatalf("UpdateSignatureAnnotations() error = %v", err)
+	}
+	if artifact, err = repo.GetArtifactManifest(ctx, desc.Digest); err != nil {
+		t.Fatalf("GetArtifactManifest() error = %v", err)
+	}
+	if _, ok := artifact.Annotations["io.example.build.url"]; ok {
+		t.Errorf("annotations = %v, want the build url removed", artifact.Annotations)
+	}
+	if desc.Digest != artifactDesc.Digest {
+		t.Errorf("UpdateSignatureAnnotations() = %v, want the original artifact %v", desc.Digest, artifactDesc.Digest)
+	}
+}
+
+func TestUpdateSignatureAnnotationsReserved(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newTestRepository(t, nil)
+	sigDesc := putTestSignature(t, repo, "signature")
+	artifactDesc, err := repo.Link(ctx, testSubject, sigDesc)
+	if err != nil {
+		t.Fatalf("Link() error = %v", err)
+	}
+	for _, key := range []string{
+		notary.AnnotationSigningMethod,
+		notary.AnnotationTimestampSignature,
+		notary.AnnotationPlatformOS,
+		notary.AnnotationPlatformArchitecture,
+		notary.AnnotationPlatformVariant,
+		"io.cncf.notary.future",
+		"io.notary.future",
+	} {
+		t.Run(key, func(t *testing.T) {
+			updates := map[string]string{
+				"io.example.build.url": "https://ci.example.com/1",
+				key:                    "value",
+			}
+			if _, err := repo.UpdateSignatureAnnotations(ctx, artifactDesc.Digest, updates); err == nil {
+				t.Fatal("UpdateSignatureAnnotations() of a reserved annotation succeeded")
+			}
+		})
+	}
+	refs, err := repo.Lookup(ctx, testSubject.Digest)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if len(refs) != 1 {
+		t.Errorf("Lookup() = %d artifacts, want 1", len(refs))
+	}
+}
+
+func TestRepositoryStats(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newTestRepository(t, nil)
+	signature := []byte("signature")
+
+	sigDesc, err := repo.Put(ctx, signature)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	artifactDesc, err := repo.Link(ctx, testSubject, sigDesc)
+	if err != nil {
+		t.Fatalf("Link() error = %v", err)
+	}
+	if _, err := repo.Lookup(ctx, testSubject.Digest); err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if _, err := repo.Get(ctx, sigDesc.Digest); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, err := repo.Get(ctx, digest.FromString("missing")); err == nil {
+		t.Fatal("Get() error = nil, want an error for a missing blob")
+	}
+	if _, err := repo.PeekArtifactType(ctx, artifactDesc.Digest); err != nil {
+		t.Fatalf("PeekArtifactType() error = %v", err)
+	}
+	if _, err := repo.GetArtifactManifest(ctx, artifactDesc.Digest); err != nil {
+		t.Fatalf("GetArtifactManifest() error = %v", err)
+	}
+	if _, err := repo.MountBlob(ctx, sigDesc.Digest, "test/other"); err != nil {
+		t.Fatalf("MountBlob() error = %v", err)
+	}
+	if _, err := repo.MountBlob(ctx, digest.FromString("missing"), "test/other"); err != nil {
+		t.Fatalf("MountBlob() error = %v", err)
+	}
+
+	got := repo.Stats()
+	want := registry.RepositoryStats{
+		LookupTotal:   1,
+		GetTotal:      4,
+		GetErrors:     1,
+		PutTotal:      1,
+		LinkTotal:     1,
+		BytesUploaded: sigDesc.Size + artifactDesc.Size,
+		CacheHits:     1,
+		CacheMisses:   1,
+	}
+	if got.BytesDownloaded < int64(len(signature))+artifactDesc.Size {
+		t.Errorf("Stats().BytesDownloaded = %d, want at least %d", got.BytesDownloaded, int64(len(signature))+artifactDesc.Size)
+	}
+	got.BytesDownloaded = 0
+	if got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+
+	repo.ResetStats()
+	if got := repo.Stats(); got != (registry.RepositoryStats{}) {
+		t.Errorf("Stats() after ResetStats() = %+v, want zero", got)
+	}
+}
+
+func TestGetBlobPresignedRedirect(t *testing.T) {
+	blob := []byte("signature")
+	blobDigest := digest.FromBytes(blob)
+
+	var followed bool
+	elsewhere := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		followed = true
+		w.Write(blob)
+	}))
+	defer elsewhere.Close()
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Query().Get("redirect") != "" {
+			http.Redirect(w, r, elsewhere.URL+"/blob", http.StatusFound)
+			return
+		}
+		w.Write(blob)
+	}))
+	defer storage.Close()
+	registryServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, storage.URL+"/blob?"+r.URL.RawQuery+"&X-Amz-Signature=sig", http.StatusTemporaryRedirect)
+	}))
+	defer registryServer.Close()
+
+	// the credentials are scoped to the registry host
+	host := strings.TrimPrefix(registryServer.URL, "http://")
+	var storageRequests int
+	tr := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host == host {
+			req.Header.Set("Authorization", "Bearer token")
+		} else {
+			storageRequests++
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	repo := registry.NewRepository(tr, host, "test/app", true)
+
+	got, err := repo.Get(context.Background(), blobDigest)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if string(got) != string(blob) {
+		t.Errorf("Get() = %q, want %q", got, blob)
+	}
+	if storageRequests != 1 {
+		t.Errorf("Get() sent %d requests to the storage with the configured transport, want 1", storageRequests)
+	}
+
+	redirecting := registry.NewRepository(http.DefaultTransport, host, "test/app", true)
+	registryServer.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, storage.URL+"/blob?redirect=1&X-Amz-Signature=sig", http.StatusTemporaryRedirect)
+	})
+	if _, err := redirecting.Get(context.Background(), blobDigest); err == nil {
+		t.Error("Get() error = nil, want an error for a further redirect")
+	}
+	if followed {
+		t.Error("Get() followed the redirect of the presigned URL")
+	}
+}
+
+// newLinkTestRepository starts a fake registry whose manifest pushes are
+// intercepted by hook before being served, and returns a repository on it
+func newLinkTestRepository(t *testing.T, hook func(w http.ResponseWriter, body []byte) bool, opts ...registry.RepositoryOption) *registry.Repository {
+	t.Helper()
+	fake := registrytest.NewServer()
+	t.Cleanup(fake.Close)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && strings.Contains(r.URL.Path, "/manifests/") {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if hook(w, body) {
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		fake.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return registry.NewRepository(http.DefaultTransport, strings.TrimPrefix(server.URL, "http://"), "test/app", true, opts...)
+}
+
+func TestBatchLinkConcurrency(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		inFlight int
+		max      int
+	)
+	repo := newLinkTestRepository(t, func(w http.ResponseWriter, body []byte) bool {
+		mu.Lock()
+		inFlight++
+		if inFlight > max {
+			max = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return false
+	}, registry.WithLinkConcurrency(2))
+
+	var signatures []oci.Descriptor
+	for i := 0; i < 6; i++ {
+		signatures = append(signatures, putTestSignature(t, repo, fmt.Sprint("signature", i)))
+	}
+	descs, err := repo.BatchLink(context.Background(), testSubject, signatures)
+	if err != nil {
+		t.Fatalf("BatchLink() error = %v", err)
+	}
+	if len(descs) != len(signatures) {
+		t.Fatalf("BatchLink() = %d descriptors, want %d", len(descs), len(signatures))
+	}
+	if max > 2 {
+		t.Errorf("BatchLink() pushed %d artifacts concurrently, want at most 2", max)
+	}
+}
+
+func TestBatchLinkPartialResult(t *testing.T) {
+	ctx := context.Background()
+	var failing digest.Digest
+	repo := newLinkTestRepository(t, func(w http.ResponseWriter, body []byte) bool {
+		if failing != "" && bytes.Contains(body, []byte(failing)) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return true
+		}
+		return false
+	})
+	signatures := []oci.Descriptor{
+		putTestSignature(t, repo, "alice"),
+		putTestSignature(t, repo, "bob"),
+		putTestSignature(t, repo, "carol"),
+	}
+	failing = signatures[1].Digest
+
+	descs, err := repo.BatchLink(ctx, testSubject, signatures)
+	var partial *notary.PartialResultError
+	if !errors.As(err, &partial) {
+		t.Fatalf("BatchLink() error = %v, want PartialResultError", err)
+	}
+	if !errors.Is(err, notary.ErrPartialResult) {
+		t.Errorf("BatchLink() error = %v, want %v", err, notary.ErrPartialResult)
+	}
+	if len(partial.Errors) != 1 || partial.Errors[failing] == nil {
+		t.Errorf("BatchLink() errors = %v, want the error of %v only", partial.Errors, failing)
+	}
+	if descs[0].Digest == "" || descs[1].Digest != "" || descs[2].Digest == "" {
+		t.Errorf("BatchLink() = %v, want the descriptors of the other signatures in order", descs)
+	}
+
+	refs, err := repo.Lookup(ctx, testSubject.Digest)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if len(refs) != 2 {
+		t.Errorf("Lookup() = %d signatures, want the 2 linked", len(refs))
+	}
+}
+
+func TestPingErrors(t *testing.T) {
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachableHost := strings.TrimPrefix(unreachable.URL, "http://")
+	unreachable.Close()
+
+	for _, tt := range []struct {
+		name    string
+		status  int
+		host    string
+		wantErr error
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, wantErr: notary.ErrUnauthorized},
+		{name: "forbidden", status: http.StatusForbidden, wantErr: notary.ErrUnauthorized},
+		{name: "unreachable", host: unreachableHost, wantErr: notary.ErrNetwork},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			host := tt.host
+			if host == "" {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(tt.status)
+				}))
+				defer server.Close()
+				host = strings.TrimPrefix(server.URL, "http://")
+			}
+			repo := registry.NewRepository(http.DefaultTransport, host, "test/app", true)
+			if err := repo.Ping(context.Background()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Ping() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPingServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	repo := registry.NewRepository(http.DefaultTransport, strings.TrimPrefix(server.URL, "http://"), "test/app", true)
+	err := repo.Ping(context.Background())
+	if err == nil {
+		t.Fatal("Ping() error = nil, want an error")
+	}
+	if errors.Is(err, notary.ErrUnauthorized) || errors.Is(err, notary.ErrNetwork) {
+		t.Errorf("Ping() error = %v, want neither unauthorized nor network", err)
+	}
+}
+
+func TestMountBlob(t *testing.T) {
+	ctx := context.Background()
+	source, server := newTestRepository(t, nil)
+	blob := []byte("layer")
+	d, err := source.PutBlob(ctx, blob)
+	if err != nil {
+		t.Fatalf("PutBlob() error = %v", err)
+	}
+	target := registry.NewRepository(http.DefaultTransport, server.Host(), "test/target", true)
+
+	mounted, err := target.MountBlob(ctx, d, source.Name())
+	if err != nil {
+		t.Fatalf("MountBlob() error = %v", err)
+	}
+	if !mounted {
+		t.Fatal("MountBlob() = false, want the known blob mounted")
+	}
+	if got, err := target.GetBlob(ctx, d); err != nil || string(got) != string(blob) {
+		t.Errorf("GetBlob() after mount = %q, %v, want %q", got, err, blob)
+	}
+
+	// the registry declines to mount an unknown blob, which is uploaded instead
+	other := []byte("other layer")
+	mounted, err = target.MountBlob(ctx, digest.FromBytes(other), source.Name())
+	if err != nil {
+		t.Fatalf("MountBlob() error = %v", err)
+	}
+	if mounted {
+		t.Fatal("MountBlob() = true, want the unknown blob not mounted")
+	}
+	d, err = target.PutBlob(ctx, other)
+	if err != nil {
+		t.Fatalf("PutBlob() after declined mount error = %v", err)
+	}
+	if got, err := target.GetBlob(ctx, d); err != nil || string(got) != string(other) {
+		t.Errorf("GetBlob() after upload = %q, %v, want %q", got, err, other)
+	}
+}
+
+func TestMountBlobError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	repo := registry.NewRepository(http.DefaultTransport, strings.TrimPrefix(server.URL, "http://"), "test/app", true)
+	if _, err := repo.MountBlob(context.Background(), digest.FromString("layer"), "test/source"); err == nil {
+		t.Error("MountBlob() error = nil, want an error")
+	}
+}
+
+// errReader fails after returning its content
+type errReader struct {
+	content []byte
+	err     error
+}
+
+func (r *errReader) Read(p []byte) (int, error) {
+	if len(r.content) == 0 {
+		return 0, r.err
+	}
+	n := copy(p, r.content)
+	r.content = r.content[n:]
+	return n, nil
+}
+
+// setenv sets the environment variable for the duration of the test
+func setenv(t *testing.T, key, value string) {
+	t.Helper()
+	old, ok := os.LookupEnv(key)
+	if err := os.Setenv(key, value); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+// assertEmptyDir fails the test if the spool directory is not cleaned up
+func assertEmptyDir(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("spool files left in %s: %d", dir, len(entries))
+	}
+}
+
+func TestPutStreamBlobUnknownSize(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	setenv(t, "TMPDIR", dir)
+	repo, _ := newTestRepository(t, nil)
+
+	blob := bytes.Repeat([]byte("layer"), 1000)
+	d, size, err := repo.PutStreamBlobUnknownSize(ctx, io.MultiReader(bytes.NewReader(blob[:10]), bytes.NewReader(blob[10:])))
+	if err != nil {
+		t.Fatalf("PutStreamBlobUnknownSize() error = %v", err)
+	}
+	if d != digest.FromBytes(blob) || size != int64(len(blob)) {
+		t.Errorf("PutStreamBlobUnknownSize() = %v, %d, want %v, %d", d, size, digest.FromBytes(blob), len(blob))
+	}
+	if got, err := repo.GetBlob(ctx, d); err != nil || !bytes.Equal(got, blob) {
+		t.Errorf("GetBlob() = %d bytes, %v, want the uploaded blob", len(got), err)
+	}
+	assertEmptyDir(t, dir)
+}
+
+func TestPutStreamBlobUnknownSizeErrors(t *testing.T) {
+	readErr := errors.New("read failed")
+	for _, tt := range []struct {
+		name    string
+		reader  io.Reader
+		status  int
+		wantErr error
+	}{
+		{name: "read error", reader: &errReader{content: []byte("partial"), err: readErr}, wantErr: readErr},
+		{name: "upload error", reader: strings.NewReader("layer"), status: http.StatusInternalServerError},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			setenv(t, "TMPDIR", dir)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+			repo := registry.NewRepository(http.DefaultTransport, strings.TrimPrefix(server.URL, "http://"), "test/app", true)
+
+			_, _, err := repo.PutStreamBlobUnknownSize(context.Background(), tt.reader)
+			if err == nil {
+				t.Fatal("PutStreamBlobUnknownSize() error = nil, want an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("PutStreamBlobUnknownSize() error = %v, want %v", err, tt.wantErr)
+			}
+			assertEmptyDir(t, dir)
+		})
+	}
+}
+
+func TestGetBlobRange(t *testing.T) {
+	blob := []byte("0123456789")
+	d := digest.FromBytes(blob)
+	// serveRange serves the range requests with 206 and a Content-Range
+	serveRange := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(blob))
+	})
+	// serveWrongRange answers with another range than requested
+	serveWrongRange := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes 0-3/%d", len(blob)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(blob[:4])
+	})
+	// ignoreRange serves the whole blob with 200
+	ignoreRange := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(blob)
+	})
+	for _, tt := range []struct {
+		name       string
+		handler    http.Handler
+		start, end int64
+		want       string
+		wantErr    bool
+	}{
+		{name: "partial content", handler: serveRange, start: 2, end: 5, want: "2345"},
+		{name: "mismatch content range", handler: serveWrongRange, start: 2, end: 5, wantErr: true},
+		{name: "range ignored", handler: ignoreRange, start: 2, end: 5, want: "2345"},
+		{name: "range ignored beyond the blob", handler: ignoreRange, start: 8, end: 12, wantErr: true},
+		{name: "invalid range", handler: serveRange, start: 5, end: 2, wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+			repo := registry.NewRepository(http.DefaultTransport, strings.TrimPrefix(server.URL, "http://"), "test/app", true)
+			got, err := repo.GetBlobRange(context.Background(), d, tt.start, tt.end)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetBlobRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("GetBlobRange() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// putTestArtifact pushes an artifact manifest of the artifact type referring
+// to testSubject with the signature blobs
+func putTestArtifact(t *testing.T, repo *registry.Repository, artifactType string, blobs ...oci.Descriptor) digest.Digest {
+	t.Helper()
+	artifact := artifactspec.Artifact{
+		Versioned:    artifactspecs.Versioned{SchemaVersion: 3},
+		MediaType:    artifactspec.MediaTypeArtifactManifest,
+		ArtifactType: artifactType,
+		SubjectManifest: artifactspec.Descriptor{
+			MediaType: testSubject.MediaType,
+			Digest:    testSubject.Digest,
+			Size:      testSubject.Size,
+		},
+	}
+	for _, blob := range blobs {
+		artifact.Blobs = append(artifact.Blobs, artifactspec.Descriptor{
+			MediaType: blob.MediaType,
+			Digest:    blob.Digest,
+			Size:      blob.Size,
+		})
+	}
+	manifest, err := registry.MarshalArtifactJSON(artifact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := repo.PutManifest(context.Background(), manifest, artifactspec.MediaTypeArtifactManifest)
+	if err != nil {
+		t.Fatalf("PutManifest() error = %v", err)
+	}
+	return d
+}
+
+func TestLookupMultiType(t *testing.T) {
+	const legacyType = "application/vnd.cncf.notary.signature"
+	repo, _ := newTestRepository(t, nil)
+	shared := putTestSignature(t, repo, "shared signature")
+	legacy := putTestSignature(t, repo, "legacy signature")
+	putTestArtifact(t, repo, registry.ArtifactTypeNotaryV2, shared)
+	putTestArtifact(t, repo, legacyType, shared, legacy)
+	putTestArtifact(t, repo, "application/vnd.example.sbom", putTestSignature(t, repo, "sbom"))
+
+	refs, err := repo.LookupMultiType(context.Background(), testSubject.Digest, []string{registry.ArtifactTypeNotaryV2, legacyType})
+	if err != nil {
+		t.Fatalf("LookupMultiType() error = %v", err)
+	}
+	got := make(map[digest.Digest][]string)
+	for _, ref := range refs {
+		got[ref.SignatureBlobDescriptor.Digest] = ref.ArtifactTypes
+	}
+	want := map[digest.Digest][]string{
+		shared.Digest: {registry.ArtifactTypeNotaryV2, legacyType},
+		legacy.Digest: {legacyType},
+	}
+	if len(refs) != len(want) || !reflect.DeepEqual(got, want) {
+		t.Errorf("LookupMultiType() artifact types = %v, want %v", got, want)
+	}
+}
+
+func TestLookupMultiTypeError(t *testing.T) {
+	const failingType = "application/vnd.example.failing"
+	fake := registrytest.NewServer()
+	defer fake.Close()
+	// the registry fails to list the referrers of one artifact type
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.RawQuery, url.QueryEscape(failingType)) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fake.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	repo := registry.NewRepository(http.DefaultTransport, strings.TrimPrefix(server.URL, "http://"), "test/app", true)
+	putTestArtifact(t, repo, registry.ArtifactTypeNotaryV2, putTestSignature(t, repo, "signature"))
+
+	refs, err := repo.LookupMultiType(context.Background(), testSubject.Digest, []string{registry.ArtifactTypeNotaryV2, failingType})
+	if err == nil {
+		t.Errorf("LookupMultiType() = %v, want an error", refs)
+	}
+}
@@ -0,0 +1,38 @@
+package registry
+
+import (
+	"crypto/x509"
+
+	"github.com/docker/libtrust"
+	"github.com/opencontainers/go-digest"
+)
+
+// VerifySchema1Manifest verifies the JWS signatures embedded in a docker image
+// manifest v2 schema 1, as produced for legacy images, and returns the
+// canonical digest of the manifest together with the signing keys.
+// If roots is not nil, the signatures must also carry certificate chains
+// leading to roots. Otherwise, the signatures are only checked to be intact,
+// and deciding whether the returned keys are trusted is up to the caller.
+func VerifySchema1Manifest(manifest []byte, roots *x509.CertPool) (digest.Digest, []libtrust.PublicKey, error) {
+	jws, err := libtrust.ParsePrettySignature(manifest, "signatures")
+	if err != nil {
+		return "", nil, err
+	}
+	keys, err := jws.Verify()
+	if err != nil {
+		return "", nil, err
+	}
+	if roots != nil {
+		if _, err := jws.VerifyChains(roots); err != nil {
+			return "", nil, err
+		}
+	}
+
+	// The digest of a signed schema 1 manifest is computed over the payload,
+	// i.e. the manifest with the signatures stripped.
+	payload, err := jws.Payload()
+	if err != nil {
+		return "", nil, err
+	}
+	return digest.FromBytes(payload), keys, nil
+}
@@ -0,0 +1,70 @@
+package registry
+
+import "sync/atomic"
+
+// RepositoryStats contains the counters of the operations on a repository.
+// Manifest and blob fetches count as Get, and manifest and blob uploads
+// as Put.
+type RepositoryStats struct {
+	LookupTotal  uint64
+	LookupErrors uint64
+	GetTotal     uint64
+	GetErrors    uint64
+	PutTotal     uint64
+	PutErrors    uint64
+	LinkTotal    uint64
+	LinkErrors   uint64
+
+	BytesDownloaded int64
+	BytesUploaded   int64
+
+	// CacheHits counts the blobs mounted from another repository of the
+	// registry, sparing the upload, and CacheMisses the mounts declined by
+	// the registry.
+	CacheHits   uint64
+	CacheMisses uint64
+}
+
+// stats holds the counters updated atomically
+type stats struct {
+	RepositoryStats
+}
+
+func (s *stats) snapshot() RepositoryStats {
+	return RepositoryStats{
+		LookupTotal:     atomic.LoadUint64(&s.LookupTotal),
+		LookupErrors:    atomic.LoadUint64(&s.LookupErrors),
+		GetTotal:        atomic.LoadUint64(&s.GetTotal),
+		GetErrors:       atomic.LoadUint64(&s.GetErrors),
+		PutTotal:        atomic.LoadUint64(&s.PutTotal),
+		PutErrors:       atomic.LoadUint64(&s.PutErrors),
+		LinkTotal:       atomic.LoadUint64(&s.LinkTotal),
+		LinkErrors:      atomic.LoadUint64(&s.LinkErrors),
+		BytesDownloaded: atomic.LoadInt64(&s.BytesDownloaded),
+		BytesUploaded:   atomic.LoadInt64(&s.BytesUploaded),
+		CacheHits:       atomic.LoadUint64(&s.CacheHits),
+		CacheMisses:     atomic.LoadUint64(&s.CacheMisses),
+	}
+}
+
+func (s *stats) reset() {
+	for _, counter := range []*uint64{
+		&s.LookupTotal, &s.LookupErrors,
+		&s.GetTotal, &s.GetErrors,
+		&s.PutTotal, &s.PutErrors,
+		&s.LinkTotal, &s.LinkErrors,
+		&s.CacheHits, &s.CacheMisses,
+	} {
+		atomic.StoreUint64(counter, 0)
+	}
+	atomic.StoreInt64(&s.BytesDownloaded, 0)
+	atomic.StoreInt64(&s.BytesUploaded, 0)
+}
+
+// count increments the total counter, and the error counter if err is not nil.
+func count(total, errors *uint64, err error) {
+	atomic.AddUint64(total, 1)
+	if err != nil {
+		atomic.AddUint64(errors, 1)
+	}
+}
@@ -0,0 +1,118 @@
+package registry
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"runtime"
+	"sync"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/auth/ambient"
+)
+
+// defaultUserAgent is the User-Agent header sent by default
+var defaultUserAgent = fmt.Sprintf("notary/%s (%s/%s)", notary.Version, runtime.GOOS, runtime.GOARCH)
+
+// userAgentTransport sets the User-Agent header on every request
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+	return t.base.RoundTrip(req)
+}
+
+// withDialer returns a copy of tr dialing with d.
+func withDialer(tr http.RoundTripper, d *net.Dialer) http.RoundTripper {
+	return withTransport(tr, "custom dialer", func(transport *http.Transport) {
+		transport.DialContext = d.DialContext
+	})
+}
+
+// withMaxConnsPerHost returns a copy of tr limited to n connections per host.
+func withMaxConnsPerHost(tr http.RoundTripper, n int) http.RoundTripper {
+	return withTransport(tr, "max connections per host", func(transport *http.Transport) {
+		transport.MaxConnsPerHost = n
+		if transport.MaxIdleConnsPerHost < n {
+			transport.MaxIdleConnsPerHost = n
+		}
+	})
+}
+
+// withTransport returns a copy of tr modified by configure. Transports other
+// than *http.Transport cannot be configured, so the returned transport fails
+// every request rather than ignoring the option.
+func withTransport(tr http.RoundTripper, option string, configure func(*http.Transport)) http.RoundTripper {
+	if tr == nil {
+		tr = http.DefaultTransport
+	}
+	transport, ok := tr.(*http.Transport)
+	if !ok {
+		return &errorTransport{
+			err: fmt.Errorf("option %s requires an *http.Transport, got %T", option, tr),
+		}
+	}
+	transport = transport.Clone()
+	configure(transport)
+	return transport
+}
+
+// errorTransport fails every request with the error of an option which
+// cannot be applied, since the constructors return no error
+type errorTransport struct {
+	err error
+}
+
+func (t *errorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, t.err
+}
+
+// warnInsecure logs a warning for a request sent over plain HTTP
+func warnInsecure(req *http.Request) {
+	log.Printf("WARNING: insecure registry: %s %s over plain HTTP", req.Method, req.URL)
+}
+
+// insecureTransport notifies every request sent over plain HTTP
+type insecureTransport struct {
+	base   http.RoundTripper
+	notice func(req *http.Request)
+}
+
+func (t *insecureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.notice(req)
+	return t.base.RoundTrip(req)
+}
+
+// ambientTransport authorizes the requests to the registry host with a bearer
+// token of the ambient credentials, obtained on the first request. The
+// requests are anonymous if no ambient credentials are available.
+type ambientTransport struct {
+	base     http.RoundTripper
+	host     string
+	provider *ambient.AmbientCredentialProvider
+
+	once  sync.Once
+	token string
+}
+
+func (t *ambientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.host {
+		return t.base.RoundTrip(req)
+	}
+	t.once.Do(func() {
+		t.token, _ = t.provider.Token(req.Context())
+	})
+	if t.token != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	return t.base.RoundTrip(req)
+}
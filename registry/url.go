@@ -0,0 +1,37 @@
+package registry
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ParseRepositoryURL parses the URL of a registry, normalizing the oci+https
+// and oci+http schemes used by ORAS and Notation to https and http.
+// The returned base path always ends with the /v2 API prefix.
+func ParseRepositoryURL(rawURL string) (scheme, host, basePath string, err error) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", "", err
+	}
+	switch u.Scheme {
+	case "https", "oci+https":
+		scheme = "https"
+	case "http", "oci+http":
+		scheme = "http"
+	default:
+		return "", "", "", fmt.Errorf("unsupported registry URL scheme: %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", "", "", fmt.Errorf("missing registry host: %q", rawURL)
+	}
+
+	basePath = strings.TrimSuffix(u.Path, "/")
+	if !strings.HasSuffix(basePath, "/v2") {
+		basePath += "/v2"
+	}
+	return scheme, u.Host, basePath, nil
+}
@@ -0,0 +1,60 @@
+package registry_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/notaryproject/notary/v2/registry"
+)
+
+func TestParseRepositoryURL(t *testing.T) {
+	for _, tt := range []struct {
+		rawURL       string
+		wantScheme   string
+		wantHost     string
+		wantBasePath string
+		wantErr      bool
+	}{
+		{rawURL: "registry.example.com", wantScheme: "https", wantHost: "registry.example.com", wantBasePath: "/v2"},
+		{rawURL: "https://registry.example.com", wantScheme: "https", wantHost: "registry.example.com", wantBasePath: "/v2"},
+		{rawURL: "oci+https://registry.example.com", wantScheme: "https", wantHost: "registry.example.com", wantBasePath: "/v2"},
+		{rawURL: "oci+http://localhost:5000", wantScheme: "http", wantHost: "localhost:5000", wantBasePath: "/v2"},
+		{rawURL: "http://localhost:5000/v2/", wantScheme: "http", wantHost: "localhost:5000", wantBasePath: "/v2"},
+		{rawURL: "oci+https://registry.example.com/mirror", wantScheme: "https", wantHost: "registry.example.com", wantBasePath: "/mirror/v2"},
+		{rawURL: "ftp://registry.example.com", wantErr: true},
+		{rawURL: "oci+https://", wantErr: true},
+	} {
+		t.Run(tt.rawURL, func(t *testing.T) {
+			scheme, host, basePath, err := registry.ParseRepositoryURL(tt.rawURL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRepositoryURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if scheme != tt.wantScheme || host != tt.wantHost || basePath != tt.wantBasePath {
+				t.Errorf("ParseRepositoryURL() = %s, %s, %s, want %s, %s, %s", scheme, host, basePath, tt.wantScheme, tt.wantHost, tt.wantBasePath)
+			}
+		})
+	}
+}
+
+func TestNewRepositoryBase(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		registry  string
+		plainHTTP bool
+		want      string
+	}{
+		{name: "host", registry: "registry.example.com", want: "https://registry.example.com/v2"},
+		{name: "host over plain HTTP", registry: "localhost:5000", plainHTTP: true, want: "http://localhost:5000/v2"},
+		{name: "oci+https", registry: "oci+https://registry.example.com", want: "https://registry.example.com/v2"},
+		{name: "oci+http", registry: "oci+http://localhost:5000", want: "http://localhost:5000/v2"},
+		{name: "scheme over plain HTTP", registry: "https://registry.example.com", plainHTTP: true, want: "https://registry.example.com/v2"},
+		{name: "base path", registry: "oci+https://registry.example.com/mirror/v2/", want: "https://registry.example.com/mirror/v2"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := registry.NewRepository(http.DefaultTransport, tt.registry, "test/app", tt.plainHTTP)
+			if got := repo.Base(); got != tt.want {
+				t.Errorf("Base() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
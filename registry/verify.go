@@ -0,0 +1,101 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/auth/ambient"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// VerificationResult is the result of VerifyImageReference
+type VerificationResult struct {
+	// Manifest describes the manifest resolved from the reference
+	Manifest oci.Descriptor
+
+	// References are the references signed by the valid signatures
+	References []string
+}
+
+// VerifyOption is an option for VerifyImageReference
+type VerifyOption func(*verifyOptions)
+
+type verifyOptions struct {
+	tr       http.RoundTripper
+	verifier notary.Verifier
+	repoOpts []RepositoryOption
+}
+
+// WithTransport sends the requests of VerifyImageReference through tr, which
+// should add the credentials required by the registry, instead of the
+// ambient credentials.
+func WithTransport(tr http.RoundTripper) VerifyOption {
+	return func(opts *verifyOptions) {
+		opts.tr = tr
+	}
+}
+
+// WithVerifier verifies the signatures with the verifier instead of the
+// verifier carried by the context.
+func WithVerifier(verifier notary.Verifier) VerifyOption {
+	return func(opts *verifyOptions) {
+		opts.verifier = verifier
+	}
+}
+
+// WithRepositoryOptions applies the options to the repository created by
+// VerifyImageReference.
+func WithRepositoryOptions(opts ...RepositoryOption) VerifyOption {
+	return func(options *verifyOptions) {
+		options.repoOpts = append(options.repoOpts, opts...)
+	}
+}
+
+// VerifyImageReference verifies the image referenced like
+// docker.io/myorg/myimage:v1.0.0 in a single call, resolving the tag to the
+// manifest and verifying that at least one of its signatures is valid.
+// By default, the requests to the registry carry a bearer token of the
+// ambient credentials, see ambient.AmbientCredentialProvider, and are
+// anonymous if none is available. The signatures are verified with the
+// verifier carried by ctx unless WithVerifier is given.
+func VerifyImageReference(ctx context.Context, ref string, opts ...VerifyOption) (VerificationResult, error) {
+	var options verifyOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	tr := options.tr
+	var ambientTr *ambientTransport
+	if tr == nil {
+		ambientTr = &ambientTransport{
+			base:     http.DefaultTransport,
+			provider: &ambient.AmbientCredentialProvider{},
+		}
+		tr = ambientTr
+	}
+	repo, reference, err := NewRepositoryFromDockerRef(tr, ref, options.repoOpts...)
+	if err != nil {
+		return VerificationResult{}, err
+	}
+	if ambientTr != nil {
+		base, err := url.Parse(repo.Base())
+		if err != nil {
+			return VerificationResult{}, err
+		}
+		ambientTr.host = base.Host
+	}
+
+	manifest, err := repo.Resolve(ctx, reference)
+	if err != nil {
+		return VerificationResult{}, err
+	}
+	_, references, err := notary.VerifyWithExitCode(ctx, repo, options.verifier, manifest, "")
+	if err != nil {
+		return VerificationResult{}, err
+	}
+	return VerificationResult{
+		Manifest:   manifest,
+		References: references,
+	}, nil
+}
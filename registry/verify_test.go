@@ -0,0 +1,138 @@
+package registry_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/docker/libtrust"
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/registry"
+	"github.com/notaryproject/notary/v2/registry/registrytest"
+	"github.com/notaryproject/notary/v2/simple"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// newTestSigningService creates a signing service with a P-256 key and a
+// self-signed certificate
+func newTestSigningService(t *testing.T) notary.SigningService {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "signer"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingKey, err := libtrust.FromCryptoPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service, err := simple.NewSigningService(signingKey, []*x509.Certificate{cert}, []*x509.Certificate{cert}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service
+}
+
+func TestVerifyImageReference(t *testing.T) {
+	ctx := context.Background()
+	server := registrytest.NewServer(registrytest.WithTLS())
+	defer server.Close()
+	tr := server.Client().Transport
+	repo := registry.NewRepository(tr, server.Host(), "test/app", false)
+
+	manifest, err := repo.PutTaggedManifest(ctx, []byte(`{"schemaVersion":2}`), oci.MediaTypeImageManifest, "v1")
+	if err != nil {
+		t.Fatalf("PutTaggedManifest() error = %v", err)
+	}
+	service := newTestSigningService(t)
+	sig, err := service.Sign(ctx, manifest)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	sigDesc, err := repo.Put(ctx, sig)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, err := repo.Link(ctx, manifest, sigDesc); err != nil {
+		t.Fatalf("Link() error = %v", err)
+	}
+
+	ref := server.Host() + "/test/app:v1"
+	got, err := registry.VerifyImageReference(ctx, ref, registry.WithTransport(tr), registry.WithVerifier(service))
+	if err != nil {
+		t.Fatalf("VerifyImageReference() error = %v", err)
+	}
+	if got.Manifest.Digest != manifest.Digest {
+		t.Fatalf("VerifyImageReference() manifest = %v, want %v", got.Manifest.Digest, manifest.Digest)
+	}
+
+	// the default transport does not trust the certificate of the registry
+	if _, err := registry.VerifyImageReference(ctx, ref, registry.WithTransport(http.DefaultTransport), registry.WithVerifier(service)); err == nil {
+		t.Fatal("VerifyImageReference() without the transport succeeded")
+	}
+}
+
+func TestVerifyImageReferenceAmbientCredentials(t *testing.T) {
+	ctx := context.Background()
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"value":"ambient-token"}`))
+	}))
+	defer tokenServer.Close()
+	setenv(t, "ACTIONS_ID_TOKEN_REQUEST_URL", tokenServer.URL)
+	setenv(t, "ACTIONS_ID_TOKEN_REQUEST_TOKEN", "request-token")
+
+	server := registrytest.NewServer()
+	defer server.Close()
+	repo := registry.NewRepository(http.DefaultTransport, server.Host(), "test/app", true)
+	manifest, err := repo.PutTaggedManifest(ctx, []byte(`{"schemaVersion":2}`), oci.MediaTypeImageManifest, "v1")
+	if err != nil {
+		t.Fatalf("PutTaggedManifest() error = %v", err)
+	}
+	service := newTestSigningService(t)
+	sig, err := service.Sign(ctx, manifest)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	sigDesc, err := repo.Put(ctx, sig)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, err := repo.Link(ctx, manifest, sigDesc); err != nil {
+		t.Fatalf("Link() error = %v", err)
+	}
+
+	ref := server.Host() + "/test/app:v1"
+	_, err = registry.VerifyImageReference(ctx, ref,
+		registry.WithVerifier(service),
+		registry.WithRepositoryOptions(registry.WithInsecureRegistry(server.Host()), registry.WithInsecureNotice(nil)),
+	)
+	if err != nil {
+		t.Fatalf("VerifyImageReference() error = %v", err)
+	}
+	if got, want := server.LastHeader().Get("Authorization"), "Bearer ambient-token"; got != want {
+		t.Errorf("VerifyImageReference() Authorization = %q, want %q", got, want)
+	}
+}
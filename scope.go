@@ -0,0 +1,120 @@
+package notary
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// ScopeViolationError is returned when signing for a repository outside of the
+// scope of the signer.
+type ScopeViolationError struct {
+	NotaryError
+	Signer       string
+	Subject      string
+	AllowedScope string
+}
+
+func (e *ScopeViolationError) Error() string {
+	return fmt.Sprintf("signer %q is not allowed to sign %s: allowed scope: %s", e.Signer, e.Subject, e.AllowedScope)
+}
+
+// ScopeValidator restricts the signing service to the repositories matching
+// any of the allowed glob patterns, e.g. registry.example.com/teamA/*.
+// The repositories are taken from the signed references, so signing without
+// references is rejected. Verification is not restricted.
+// The signed references do not bind the repository the signature is linked
+// into, which is checked by CheckRepository, e.g. by NotaryV2Client.
+type ScopeValidator struct {
+	Service SigningService
+
+	// Name identifies the signer in errors
+	Name string
+
+	// AllowedScopes are the glob patterns of the allowed repositories, in the
+	// syntax of path.Match
+	AllowedScopes []string
+}
+
+// Sign signs the descriptor if all references are in the allowed scopes.
+func (v *ScopeValidator) Sign(ctx context.Context, desc oci.Descriptor, opts ...SignOption) ([]byte, error) {
+	if err := v.checkReferences(desc, opts); err != nil {
+		return nil, err
+	}
+	return v.Service.Sign(ctx, desc, opts...)
+}
+
+// SignWithAnnotations signs like Sign, and passes on the annotations of the
+// signing service if it provides any, e.g. a FallbackSigner.
+func (v *ScopeValidator) SignWithAnnotations(ctx context.Context, desc oci.Descriptor, opts ...SignOption) ([]byte, map[string]string, error) {
+	if err := v.checkReferences(desc, opts); err != nil {
+		return nil, nil, err
+	}
+	return signWithAnnotations(ctx, v.Service, desc, opts...)
+}
+
+func (v *ScopeValidator) checkReferences(desc oci.Descriptor, opts []SignOption) error {
+	references := NewSignOptions(opts...).References
+	if len(references) == 0 {
+		return v.violation(desc.Digest.String())
+	}
+	for _, reference := range references {
+		if !v.allowed(repositoryName(reference)) {
+			return v.violation(reference)
+		}
+	}
+	return nil
+}
+
+// CheckRepository checks that the repository the signatures are linked into is
+// in the allowed scopes. The repository must implement NamedRepository.
+func (v *ScopeValidator) CheckRepository(repo SignatureRepository) error {
+	named, ok := repo.(NamedRepository)
+	if !ok {
+		return v.violation("unnamed repository")
+	}
+	if name := named.FullName(); !v.allowed(name) {
+		return v.violation(name)
+	}
+	return nil
+}
+
+// Verify verifies with the signing service.
+func (v *ScopeValidator) Verify(ctx context.Context, desc oci.Descriptor, signature []byte) ([]string, error) {
+	return v.Service.Verify(ctx, desc, signature)
+}
+
+func (v *ScopeValidator) allowed(repository string) bool {
+	for _, scope := range v.AllowedScopes {
+		if matched, err := path.Match(scope, repository); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *ScopeValidator) violation(subject string) error {
+	return &ScopeViolationError{
+		NotaryError: NotaryError{
+			Code: ErrCodePolicyViolation,
+			Op:   "sign",
+		},
+		Signer:       v.Name,
+		Subject:      subject,
+		AllowedScope: strings.Join(v.AllowedScopes, ", "),
+	}
+}
+
+// repositoryName strips the tag and the digest from the reference
+func repositoryName(reference string) string {
+	if i := strings.Index(reference, "@"); i >= 0 {
+		reference = reference[:i]
+	}
+	if i := strings.LastIndex(reference, ":"); i > strings.LastIndex(reference, "/") {
+		reference = reference[:i]
+	}
+	return reference
+}
@@ -0,0 +1,61 @@
+package notary_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/registry"
+	"github.com/notaryproject/notary/v2/registry/registrytest"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestNotaryV2ClientAllowedScopes(t *testing.T) {
+	service := newTestSigningService(t, "teamA")
+	subject := oci.Descriptor{
+		MediaType: oci.MediaTypeImageManifest,
+		Digest:    digest.FromString("app"),
+		Size:      3,
+	}
+	for _, tt := range []struct {
+		name      string
+		repo      string
+		reference string
+		wantErr   bool
+	}{
+		{name: "in scope", repo: "teama/app", reference: "registry.example.com/teama/app:v1"},
+		{name: "reference out of scope", repo: "teama/app", reference: "registry.example.com/teamb/app:v1", wantErr: true},
+		{name: "repository out of scope", repo: "teamb/app", reference: "registry.example.com/teama/app:v1", wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server := registrytest.NewServer()
+			defer server.Close()
+			// the certificate of the signer is valid for registry.example.com only
+			scopes := []string{server.Host() + "/teama/*", "registry.example.com/teama/*"}
+			repo := registry.NewRepository(http.DefaultTransport, server.Host(), tt.repo, true)
+			client := notary.NewClient(repo, service, notary.WithAllowedScopes("teamA", scopes...))
+
+			_, err := client.Sign(ctx, subject, notary.WithReferences(tt.reference))
+			if tt.wantErr {
+				if !errors.Is(err, notary.ErrPolicyViolation) {
+					t.Fatalf("Sign() error = %v, want %v", err, notary.ErrPolicyViolation)
+				}
+				refs, err := repo.Lookup(ctx, subject.Digest)
+				if err != nil {
+					t.Fatalf("Lookup() error = %v", err)
+				}
+				if len(refs) != 0 {
+					t.Fatalf("Lookup() = %v, want no signature linked", refs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package notary
+
+import (
+	"sort"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// SignatureSet is a set of signatures keyed by the signature blob digest
+type SignatureSet struct {
+	refs map[digest.Digest]SignatureRef
+}
+
+// NewSignatureSet creates a signature set with the given signatures
+func NewSignatureSet(refs ...SignatureRef) *SignatureSet {
+	s := &SignatureSet{
+		refs: make(map[digest.Digest]SignatureRef, len(refs)),
+	}
+	for _, ref := range refs {
+		s.Add(ref)
+	}
+	return s
+}
+
+// Add adds a signature to the set
+func (s *SignatureSet) Add(ref SignatureRef) {
+	s.refs[ref.SignatureBlobDescriptor.Digest] = ref
+}
+
+// Remove removes the signature with the specified blob digest from the set
+func (s *SignatureSet) Remove(signatureDigest digest.Digest) {
+	delete(s.refs, signatureDigest)
+}
+
+// Contains tells whether the signature with the specified blob digest is in the set
+func (s *SignatureSet) Contains(signatureDigest digest.Digest) bool {
+	_, ok := s.refs[signatureDigest]
+	return ok
+}
+
+// Len returns the number of signatures in the set
+func (s *SignatureSet) Len() int {
+	return len(s.refs)
+}
+
+// Union returns a new set with the signatures in either set
+func (s *SignatureSet) Union(other *SignatureSet) *SignatureSet {
+	result := NewSignatureSet()
+	for _, ref := range s.refs {
+		result.Add(ref)
+	}
+	for _, ref := range other.refs {
+		result.Add(ref)
+	}
+	return result
+}
+
+// Intersection returns a new set with the signatures in both sets
+func (s *SignatureSet) Intersection(other *SignatureSet) *SignatureSet {
+	result := NewSignatureSet()
+	for signatureDigest, ref := range s.refs {
+		if other.Contains(signatureDigest) {
+			result.Add(ref)
+		}
+	}
+	return result
+}
+
+// Difference returns a new set with the signatures in this set but not in the other
+func (s *SignatureSet) Difference(other *SignatureSet) *SignatureSet {
+	result := NewSignatureSet()
+	for signatureDigest, ref := range s.refs {
+		if !other.Contains(signatureDigest) {
+			result.Add(ref)
+		}
+	}
+	return result
+}
+
+// ToSlice returns the signatures in the set ordered by the signature blob digest
+func (s *SignatureSet) ToSlice() []SignatureRef {
+	refs := make([]SignatureRef, 0, len(s.refs))
+	for _, ref := range s.refs {
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].SignatureBlobDescriptor.Digest < refs[j].SignatureBlobDescriptor.Digest
+	})
+	return refs
+}
@@ -0,0 +1,77 @@
+package notary_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func testSignatureRef(content string) notary.SignatureRef {
+	return notary.SignatureRef{
+		SignatureBlobDescriptor: oci.Descriptor{
+			Digest: digest.FromString(content),
+			Size:   int64(len(content)),
+		},
+	}
+}
+
+// setDigests returns the blob digests of the set in order
+func setDigests(s *notary.SignatureSet) []digest.Digest {
+	digests := []digest.Digest{}
+	for _, ref := range s.ToSlice() {
+		digests = append(digests, ref.SignatureBlobDescriptor.Digest)
+	}
+	return digests
+}
+
+func TestSignatureSet(t *testing.T) {
+	a, b, c := testSignatureRef("a"), testSignatureRef("b"), testSignatureRef("c")
+	s := notary.NewSignatureSet(a, b, a)
+	if s.Len() != 2 {
+		t.Fatalf("Len() = %d, want the duplicate counted once", s.Len())
+	}
+	if !s.Contains(a.SignatureBlobDescriptor.Digest) || s.Contains(c.SignatureBlobDescriptor.Digest) {
+		t.Errorf("Contains() = wrong membership of %v", setDigests(s))
+	}
+	s.Remove(a.SignatureBlobDescriptor.Digest)
+	s.Remove(c.SignatureBlobDescriptor.Digest)
+	if s.Len() != 1 || s.Contains(a.SignatureBlobDescriptor.Digest) {
+		t.Errorf("Remove() left %v, want only b", setDigests(s))
+	}
+
+	refs := notary.NewSignatureSet(c, b, a).ToSlice()
+	for i := 1; i < len(refs); i++ {
+		if refs[i-1].SignatureBlobDescriptor.Digest > refs[i].SignatureBlobDescriptor.Digest {
+			t.Fatalf("ToSlice() = %v, want ordered by digest", refs)
+		}
+	}
+}
+
+func TestSignatureSetOperations(t *testing.T) {
+	a, b, c := testSignatureRef("a"), testSignatureRef("b"), testSignatureRef("c")
+	left := notary.NewSignatureSet(a, b)
+	right := notary.NewSignatureSet(b, c)
+	for _, tt := range []struct {
+		name string
+		got  *notary.SignatureSet
+		want *notary.SignatureSet
+	}{
+		{name: "union", got: left.Union(right), want: notary.NewSignatureSet(a, b, c)},
+		{name: "intersection", got: left.Intersection(right), want: notary.NewSignatureSet(b)},
+		{name: "difference", got: left.Difference(right), want: notary.NewSignatureSet(a)},
+		{name: "empty intersection", got: left.Intersection(notary.NewSignatureSet(c)), want: notary.NewSignatureSet()},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, want := setDigests(tt.got), setDigests(tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s = %v, want %v", tt.name, got, want)
+			}
+		})
+	}
+	// the operands are left untouched
+	if left.Len() != 2 || right.Len() != 2 {
+		t.Errorf("operands changed: %v, %v", setDigests(left), setDigests(right))
+	}
+}
@@ -0,0 +1,29 @@
+package signature
+
+import (
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// SignedContentDigest returns the digest of the content covered by the token
+// without verifying the signature. It is intended for indexing and searching,
+// and must not be used for trust decisions.
+func SignedContentDigest(token []byte) (digest.Digest, error) {
+	claims, err := UnverifiedClaims(token)
+	if err != nil {
+		return "", err
+	}
+	return digest.Parse(claims.Manifest.Digest)
+}
+
+// UnverifiedClaims returns the claims of the token without verifying the
+// signature. It is intended for display and indexing, and must not be used
+// for trust decisions.
+func UnverifiedClaims(token []byte) (Claims, error) {
+	parts := strings.Split(string(token), ".")
+	if len(parts) != 3 {
+		return Claims{}, ErrInvalidToken
+	}
+	return DecodeClaims(parts[1])
+}
@@ -1,14 +1,48 @@
 package signature
 
-import "errors"
+import (
+	"errors"
+	"time"
 
-// common errors
+	"github.com/notaryproject/notary/v2/errcode"
+)
+
+// common errors, which match the notary sentinel errors of their codes
 var (
-	ErrInvalidToken         = errors.New("invalid token")
-	ErrInvalidSignatureType = errors.New("invalid signature type")
-	ErrUnknownSignatureType = errors.New("unknown signature type")
-	ErrUnknownSigner        = errors.New("unknown signer")
-	ErrDigestMismatch       = errors.New("digest mismatch")
-	ErrSizeMismatch         = errors.New("size mismatch")
-	ErrMediaTypeMismatch    = errors.New("media type mismatch")
+	ErrInvalidToken         = newError(errcode.InvalidFormat, "invalid token")
+	ErrInvalidSignatureType = newError(errcode.InvalidFormat, "invalid signature type")
+	ErrUnknownSignatureType = newError(errcode.InvalidFormat, "unknown signature type")
+	ErrUnknownSigner        = newError(errcode.NotFound, "unknown signer")
+	ErrDigestMismatch       = newError(errcode.DigestMismatch, "digest mismatch")
+	ErrSizeMismatch         = newError(errcode.DigestMismatch, "size mismatch")
+	ErrMediaTypeMismatch    = newError(errcode.DigestMismatch, "media type mismatch")
 )
+
+func newError(code errcode.ErrorCode, msg string) error {
+	return &errcode.NotaryError{
+		Code:  code,
+		Cause: errors.New(msg),
+	}
+}
+
+// SignatureNotYetValidError is returned when a signature is verified before
+// its not before time.
+type SignatureNotYetValidError struct {
+	errcode.NotaryError
+	NotBefore time.Time
+}
+
+func (e *SignatureNotYetValidError) Error() string {
+	return "signature is not valid until " + e.NotBefore.Format(time.RFC3339)
+}
+
+// SignatureExpiredError is returned when a signature is verified after its
+// expiry time.
+type SignatureExpiredError struct {
+	errcode.NotaryError
+	Expiry time.Time
+}
+
+func (e *SignatureExpiredError) Error() string {
+	return "signature expired at " + e.Expiry.Format(time.RFC3339)
+}
@@ -0,0 +1,66 @@
+package signature_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/errcode"
+	"github.com/notaryproject/notary/v2/signature"
+)
+
+func TestErrorCodes(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "invalid token", err: signature.ErrInvalidToken, want: notary.ErrInvalidFormat},
+		{name: "unknown signature format", err: signature.ErrUnknownSignatureFormat, want: notary.ErrInvalidFormat},
+		{name: "unknown signer", err: signature.ErrUnknownSigner, want: notary.ErrNotFound},
+		{name: "digest mismatch", err: signature.ErrDigestMismatch, want: notary.ErrDigestMismatch},
+		{name: "unknown format", err: signature.ErrUnknownSignatureFormat, want: notary.ErrInvalidFormat},
+		{name: "missing nonce", err: signature.ErrMissingNonce, want: notary.ErrInvalidFormat},
+		{
+			name: "not yet valid",
+			err: &signature.SignatureNotYetValidError{
+				NotaryError: errcode.NotaryError{Code: errcode.NotYetValid},
+				NotBefore:   time.Now(),
+			},
+			want: notary.ErrNotYetValid,
+		},
+		{
+			name: "expired",
+			err: &signature.SignatureExpiredError{
+				NotaryError: errcode.NotaryError{Code: errcode.Expired},
+				Expiry:      time.Now(),
+			},
+			want: notary.ErrExpired,
+		},
+		{
+			name: "replayed nonce",
+			err: &signature.ReplayedNonceError{
+				NotaryError: errcode.NotaryError{Code: errcode.Replayed},
+				Nonce:       "nonce",
+			},
+			want: notary.ErrReplayed,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := fmt.Errorf("verification failure: %w", tt.err)
+			if !errors.Is(wrapped, tt.want) {
+				t.Errorf("errors.Is(%v, %v) = false", wrapped, tt.want)
+			}
+			if !errors.Is(wrapped, tt.err) {
+				t.Errorf("errors.Is(%v, %v) = false", wrapped, tt.err)
+			}
+		})
+	}
+
+	// errors of the same code are told apart by identity
+	if errors.Is(signature.ErrMissingNonce, signature.ErrInvalidToken) {
+		t.Error("errors.Is(ErrMissingNonce, ErrInvalidToken) = true")
+	}
+}
@@ -0,0 +1,40 @@
+package signature
+
+import (
+	"bytes"
+
+	"github.com/notaryproject/notary/v2/errcode"
+)
+
+// SignatureFormat is the envelope format of a signature
+type SignatureFormat string
+
+// Signature formats
+const (
+	FormatJWS     SignatureFormat = "jws"
+	FormatCOSE    SignatureFormat = "cose"
+	FormatUnknown SignatureFormat = "unknown"
+)
+
+// ErrUnknownSignatureFormat is returned when the envelope format of a
+// signature cannot be detected.
+var ErrUnknownSignatureFormat = newError(errcode.InvalidFormat, "unknown signature format")
+
+// DetectSignatureFormat detects the envelope format from the leading bytes of
+// the signature. JWS signatures are recognized in both the JSON serialization,
+// starting with '{', and the compact serialization, starting with the base64url
+// encoded '{"' of the header. COSE signatures start with the COSE_Sign1 tag
+// 0xd2, or the CBOR array header 0x84 if untagged.
+func DetectSignatureFormat(data []byte) (SignatureFormat, error) {
+	if len(data) == 0 {
+		return FormatUnknown, ErrUnknownSignatureFormat
+	}
+	switch {
+	case data[0] == '{', bytes.HasPrefix(data, []byte("eyJ")):
+		return FormatJWS, nil
+	case data[0] == 0xd2, data[0] == 0x84:
+		return FormatCOSE, nil
+	default:
+		return FormatUnknown, ErrUnknownSignatureFormat
+	}
+}
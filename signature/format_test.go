@@ -0,0 +1,37 @@
+package signature_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/notaryproject/notary/v2/signature"
+)
+
+func TestDetectSignatureFormat(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		data    []byte
+		want    signature.SignatureFormat
+		wantErr bool
+	}{
+		{name: "JWS JSON", data: []byte(`{"payload":"e30"}`), want: signature.FormatJWS},
+		{name: "JWS compact", data: []byte("eyJhbGciOiJFUzI1NiJ9.e30.c2ln"), want: signature.FormatJWS},
+		{name: "COSE tagged", data: []byte{0xd2, 0x84, 0x43}, want: signature.FormatCOSE},
+		{name: "COSE untagged", data: []byte{0x84, 0x43}, want: signature.FormatCOSE},
+		{name: "empty", data: nil, want: signature.FormatUnknown, wantErr: true},
+		{name: "unknown", data: []byte("-----BEGIN PGP SIGNATURE-----"), want: signature.FormatUnknown, wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := signature.DetectSignatureFormat(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DetectSignatureFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("DetectSignatureFormat() = %v, want %v", got, tt.want)
+			}
+			if tt.wantErr && !errors.Is(err, signature.ErrUnknownSignatureFormat) {
+				t.Errorf("DetectSignatureFormat() error = %v, want %v", err, signature.ErrUnknownSignatureFormat)
+			}
+		})
+	}
+}
@@ -9,9 +9,14 @@ type Header struct {
 // Claims contains the claims to be signed
 type Claims struct {
 	Manifest
-	Expiration int64 `json:"exp,omitempty"`
-	IssuedAt   int64 `json:"iat,omitempty"`
-	NotBefore  int64 `json:"nbf,omitempty"`
+	Expiration int64  `json:"exp,omitempty"`
+	IssuedAt   int64  `json:"iat,omitempty"`
+	NotBefore  int64  `json:"nbf,omitempty"`
+	Nonce      string `json:"nonce,omitempty"`
+
+	// EmbeddedPublicKey is the base64 encoded DER SubjectPublicKeyInfo of the
+	// signer for self-contained signatures
+	EmbeddedPublicKey string `json:"io.notary.embedded-public-key,omitempty"`
 }
 
 // Manifest to be signed
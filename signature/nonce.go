@@ -0,0 +1,126 @@
+package signature
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/notaryproject/notary/v2/errcode"
+)
+
+// ErrMissingNonce is returned when the replay of a token is checked but the
+// claims carry no nonce.
+var ErrMissingNonce = newError(errcode.InvalidFormat, "missing nonce")
+
+// ReplayedNonceError is returned when a nonce is seen twice within the window
+type ReplayedNonceError struct {
+	errcode.NotaryError
+	Nonce string
+}
+
+func (e *ReplayedNonceError) Error() string {
+	return "replayed nonce: " + e.Nonce
+}
+
+// NewNonce generates a cryptographically random 128-bit nonce
+func NewNonce() (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(nonce), nil
+}
+
+// NonceRegistry records the nonces seen within a sliding window
+type NonceRegistry struct {
+	window time.Duration
+	path   string
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// NewNonceRegistry creates an in-memory nonce registry
+func NewNonceRegistry(window time.Duration) *NonceRegistry {
+	return &NonceRegistry{
+		window: window,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// NewFileNonceRegistry creates a nonce registry persisted to the file at path,
+// so that it survives restarts. The file is created if it does not exist.
+func NewFileNonceRegistry(path string, window time.Duration) (*NonceRegistry, error) {
+	r := NewNonceRegistry(window)
+	r.path = path
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return r, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &r.seen); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Check records the nonce, and returns ReplayedNonceError if it has been seen
+// within the window. If the nonce cannot be persisted, it is not recorded.
+func (r *NonceRegistry) Check(nonce string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for seen, at := range r.seen {
+		if now.Sub(at) > r.window {
+			delete(r.seen, seen)
+		}
+	}
+	if _, found := r.seen[nonce]; found {
+		return &ReplayedNonceError{
+			NotaryError: errcode.NotaryError{
+				Code: errcode.Replayed,
+				Op:   "check nonce",
+			},
+			Nonce: nonce,
+		}
+	}
+	r.seen[nonce] = now
+	if err := r.save(); err != nil {
+		delete(r.seen, nonce)
+		return err
+	}
+	return nil
+}
+
+// save persists the nonces if the registry is file-backed.
+// The caller must hold the lock.
+func (r *NonceRegistry) save() error {
+	if r.path == "" {
+		return nil
+	}
+	raw, err := json.Marshal(r.seen)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(r.path), filepath.Base(r.path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
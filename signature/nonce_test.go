@@ -0,0 +1,126 @@
+package signature
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSigner and testVerifier implement a trivial signature type
+type testSigner struct{}
+
+func (testSigner) Sign(claims string) (string, []byte, error) {
+	header := EncodeSegment([]byte(`{"typ":"test"}`))
+	return header + "." + claims, []byte("signature"), nil
+}
+
+type testVerifier struct{}
+
+func (testVerifier) Type() string {
+	return "test"
+}
+
+func (testVerifier) Verify(header Header, signed string, sig []byte) error {
+	if string(sig) != "signature" {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func newTestScheme(nonces *NonceRegistry) *Scheme {
+	scheme := NewScheme()
+	scheme.RegisterSigner("", testSigner{})
+	scheme.RegisterVerifier(testVerifier{})
+	if nonces != nil {
+		scheme.RegisterNonceRegistry(nonces)
+	}
+	return scheme
+}
+
+func TestNonceRegistryCheck(t *testing.T) {
+	r := NewNonceRegistry(time.Hour)
+	if err := r.Check("nonce"); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	var replayed *ReplayedNonceError
+	if err := r.Check("nonce"); !errors.As(err, &replayed) {
+		t.Fatalf("Check() of a replayed nonce error = %v, want ReplayedNonceError", err)
+	}
+
+	// nonces are forgotten after the window
+	r.seen["nonce"] = time.Now().Add(-2 * time.Hour)
+	if err := r.Check("nonce"); err != nil {
+		t.Fatalf("Check() after the window error = %v", err)
+	}
+}
+
+func TestFileNonceRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonces.json")
+	r, err := NewFileNonceRegistry(path, time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileNonceRegistry() error = %v", err)
+	}
+	if err := r.Check("nonce"); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	reloaded, err := NewFileNonceRegistry(path, time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileNonceRegistry() error = %v", err)
+	}
+	var replayed *ReplayedNonceError
+	if err := reloaded.Check("nonce"); !errors.As(err, &replayed) {
+		t.Fatalf("Check() after reload error = %v, want ReplayedNonceError", err)
+	}
+}
+
+func TestNonceRegistrySaveFailure(t *testing.T) {
+	r, err := NewFileNonceRegistry(filepath.Join(t.TempDir(), "missing", "nonces.json"), time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileNonceRegistry() error = %v", err)
+	}
+	if err := r.Check("nonce"); err == nil {
+		t.Fatal("Check() succeeded, want save error")
+	}
+	if _, found := r.seen["nonce"]; found {
+		t.Fatal("Check() recorded the nonce although it was not saved")
+	}
+}
+
+func TestSchemeVerifyReplayCheck(t *testing.T) {
+	scheme := newTestScheme(NewNonceRegistry(time.Hour))
+	token, err := scheme.Sign("", Claims{Nonce: "nonce"})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	// verification without the replay check can be repeated
+	for i := 0; i < 2; i++ {
+		if _, err := scheme.Verify(token); err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+	}
+
+	if _, err := scheme.Verify(token, WithReplayCheck()); err != nil {
+		t.Fatalf("Verify() with replay check error = %v", err)
+	}
+	var replayed *ReplayedNonceError
+	if _, err := scheme.Verify(token, WithReplayCheck()); !errors.As(err, &replayed) {
+		t.Fatalf("Verify() of a replayed token error = %v, want ReplayedNonceError", err)
+	}
+}
+
+func TestSchemeVerifyReplayCheckErrors(t *testing.T) {
+	withoutNonce, err := newTestScheme(nil).Sign("", Claims{})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if _, err := newTestScheme(NewNonceRegistry(time.Hour)).Verify(withoutNonce, WithReplayCheck()); !errors.Is(err, ErrMissingNonce) {
+		t.Fatalf("Verify() without nonce error = %v, want %v", err, ErrMissingNonce)
+	}
+	if _, err := newTestScheme(nil).Verify(withoutNonce, WithReplayCheck()); err == nil || !strings.Contains(err.Error(), "nonce registry") {
+		t.Fatalf("Verify() without nonce registry error = %v, want missing nonce registry", err)
+	}
+}
@@ -1,17 +1,19 @@
 package signature
 
 import (
-	"fmt"
+	"errors"
 	"strings"
 	"time"
 
 	"github.com/docker/go/canonical/json"
+	"github.com/notaryproject/notary/v2/errcode"
 )
 
 // Scheme is a signature scheme
 type Scheme struct {
 	signers   map[string]Signer
 	verifiers map[string]Verifier
+	nonces    *NonceRegistry
 }
 
 // NewScheme creates a new scheme
@@ -32,6 +34,38 @@ func (s *Scheme) RegisterVerifier(verifier Verifier) {
 	s.verifiers[verifier.Type()] = verifier
 }
 
+// RegisterNonceRegistry registers the nonce registry to reject replayed
+// signatures on verification with WithReplayCheck
+func (s *Scheme) RegisterNonceRegistry(nonces *NonceRegistry) {
+	s.nonces = nonces
+}
+
+// VerifyOption configures the verification of a token
+type VerifyOption func(*verifyOptions)
+
+type verifyOptions struct {
+	replayCheck bool
+	verifier    Verifier
+}
+
+// WithReplayCheck accepts the token only once: the nonce of the token is
+// recorded by the registered nonce registry, and a token whose nonce has been
+// seen within the window is rejected. It must not be used to verify the same
+// token again, e.g. on retries.
+func WithReplayCheck() VerifyOption {
+	return func(opts *verifyOptions) {
+		opts.replayCheck = true
+	}
+}
+
+// WithVerifier verifies the token with the verifier in place of the registered
+// verifier of the same type, e.g. to trust other roots for a single call.
+func WithVerifier(verifier Verifier) VerifyOption {
+	return func(opts *verifyOptions) {
+		opts.verifier = verifier
+	}
+}
+
 // Sign signs claims by a signer
 func (s *Scheme) Sign(signerID string, claims Claims) (string, error) {
 	bytes, err := json.MarshalCanonical(claims)
@@ -60,13 +94,18 @@ func (s *Scheme) SignRaw(signerID string, content []byte) (string, error) {
 }
 
 // Verify verifies the JWT-like token
-func (s *Scheme) Verify(token string) (Claims, error) {
+func (s *Scheme) Verify(token string, opts ...VerifyOption) (Claims, error) {
+	var options verifyOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	parts := strings.Split(token, ".")
 	if len(parts) != 3 {
 		return Claims{}, ErrInvalidToken
 	}
 
-	if err := s.verifySignature(parts); err != nil {
+	if err := s.verifySignature(parts, options.verifier); err != nil {
 		return Claims{}, err
 	}
 
@@ -75,10 +114,10 @@ func (s *Scheme) Verify(token string) (Claims, error) {
 		return Claims{}, err
 	}
 
-	return claims, s.verifyClaims(claims)
+	return claims, s.verifyClaims(claims, options)
 }
 
-func (s *Scheme) verifySignature(parts []string) error {
+func (s *Scheme) verifySignature(parts []string, override Verifier) error {
 	rawHeader, err := DecodeSegment(parts[0])
 	if err != nil {
 		return ErrInvalidToken
@@ -90,6 +129,9 @@ func (s *Scheme) verifySignature(parts []string) error {
 	header.Raw = rawHeader
 
 	verifier, found := s.verifiers[header.Type]
+	if override != nil && override.Type() == header.Type {
+		verifier, found = override, true
+	}
 	if !found {
 		return ErrUnknownSignatureType
 	}
@@ -106,13 +148,41 @@ func (s *Scheme) verifySignature(parts []string) error {
 	)
 }
 
-func (s *Scheme) verifyClaims(claims Claims) error {
+func (s *Scheme) verifyClaims(claims Claims, options verifyOptions) error {
 	now := time.Now().Unix()
 	if claims.Expiration != 0 && now > claims.Expiration {
-		return fmt.Errorf("content expired: %d: current: %d", claims.Expiration, now)
+		return &SignatureExpiredError{
+			NotaryError: errcode.NotaryError{
+				Code: errcode.Expired,
+				Op:   "verify",
+			},
+			Expiry: time.Unix(claims.Expiration, 0),
+		}
 	}
 	if claims.NotBefore != 0 && now < claims.NotBefore {
-		return fmt.Errorf("content is not available yet: %d: current: %d", claims.NotBefore, now)
+		return &SignatureNotYetValidError{
+			NotaryError: errcode.NotaryError{
+				Code: errcode.NotYetValid,
+				Op:   "verify",
+			},
+			NotBefore: time.Unix(claims.NotBefore, 0),
+		}
+	}
+	if options.replayCheck {
+		return s.CheckReplay(claims)
 	}
 	return nil
 }
+
+// CheckReplay records the nonce of the verified claims with the registered
+// nonce registry, and returns ReplayedNonceError if it has been seen within
+// the window. See WithReplayCheck.
+func (s *Scheme) CheckReplay(claims Claims) error {
+	if s.nonces == nil {
+		return errors.New("replay check requires a nonce registry")
+	}
+	if claims.Nonce == "" {
+		return ErrMissingNonce
+	}
+	return s.nonces.Check(claims.Nonce)
+}
@@ -0,0 +1,203 @@
+package x509
+
+import (
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/docker/go/canonical/json"
+	"github.com/docker/libtrust"
+	"github.com/notaryproject/notary/v2/signature"
+)
+
+// DelegationContentType is the cty header of delegation tokens, which tells
+// them apart from signatures made with the same root key
+const DelegationContentType = "application/vnd.cncf.notary.delegation.v1+jwt"
+
+// DelegationToken is a token signed by a root signer, which grants a delegate
+// the authority to sign the repositories in its scope for a limited time.
+// It is encoded like a signature, with the DelegationContentType header.
+type DelegationToken string
+
+// DelegationClaims are the claims of a delegation token
+type DelegationClaims struct {
+	// Scope is the path pattern of the repositories the delegate may sign,
+	// e.g. registry.example.com/team/*
+	Scope string `json:"scope"`
+
+	// Subject is the subject of the delegate certificate
+	Subject string `json:"sub"`
+
+	// Fingerprint is the fingerprint of the delegate certificate, which binds
+	// the token to the key of the delegate
+	Fingerprint string `json:"x5t#S256"`
+
+	IssuedAt   int64 `json:"iat"`
+	Expiration int64 `json:"exp"`
+}
+
+// IssueDelegationToken issues a token signed by rootSigner, which delegates
+// the authority to sign the repositories matching scope to the holder of the
+// delegate certificate for the validity period, e.g. for a CI job, without
+// handing out the root key. The root signer must be created by this package.
+func IssueDelegationToken(delegate *x509.Certificate, scope string, validity time.Duration, rootSigner signature.Signer) (DelegationToken, error) {
+	if _, err := path.Match(scope, ""); err != nil {
+		return "", fmt.Errorf("invalid scope %q: %v", scope, err)
+	}
+	if validity <= 0 {
+		return "", errors.New("validity must be positive")
+	}
+	now := time.Now()
+	return signDelegationClaims(DelegationClaims{
+		Scope:       scope,
+		Subject:     delegate.Subject.String(),
+		Fingerprint: Fingerprint(delegate),
+		IssuedAt:    now.Unix(),
+		Expiration:  now.Add(validity).Unix(),
+	}, rootSigner)
+}
+
+// signDelegationClaims signs the claims as a delegation token
+func signDelegationClaims(claims DelegationClaims, rootSigner signature.Signer) (DelegationToken, error) {
+	root, ok := rootSigner.(*signer)
+	if !ok {
+		return "", errors.New("root signer must be an x509 signer")
+	}
+	rawClaims, err := json.MarshalCanonical(claims)
+	if err != nil {
+		return "", err
+	}
+	signed, sig, err := root.sign(signature.EncodeSegment(rawClaims), DelegationContentType)
+	if err != nil {
+		return "", err
+	}
+	return DelegationToken(signed + "." + signature.EncodeSegment(sig)), nil
+}
+
+// VerifyWithDelegation verifies the signature made by a delegate under the
+// delegation token, which is verified by rootVerifier. The signature must be
+// signed by the key of the delegate certificate carried in its x5c header,
+// within the validity of the token, and for references in the scope of the
+// token only. On success, the claims of the signature are returned.
+// The token must be valid at present. The signing time of the signature is
+// its iat claim, which is asserted by the delegate itself, so a delegate can
+// backdate signatures into the validity of a token it once held.
+func VerifyWithDelegation(sig []byte, token DelegationToken, rootVerifier signature.Verifier) (signature.Claims, error) {
+	delegation, err := verifyDelegationToken(token, rootVerifier)
+	if err != nil {
+		return signature.Claims{}, fmt.Errorf("invalid delegation token: %w", err)
+	}
+
+	parts := strings.Split(string(sig), ".")
+	if len(parts) != 3 {
+		return signature.Claims{}, signature.ErrInvalidToken
+	}
+	rawHeader, err := signature.DecodeSegment(parts[0])
+	if err != nil {
+		return signature.Claims{}, signature.ErrInvalidToken
+	}
+	var header Header
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return signature.Claims{}, signature.ErrInvalidToken
+	}
+	if header.Type != Type {
+		return signature.Claims{}, signature.ErrInvalidSignatureType
+	}
+	if len(header.X5c) == 0 {
+		return signature.Claims{}, errors.New("missing delegate certificate")
+	}
+	cert, err := x509.ParseCertificate(header.X5c[0])
+	if err != nil {
+		return signature.Claims{}, err
+	}
+	if Fingerprint(cert) != delegation.Fingerprint {
+		return signature.Claims{}, errors.New("signature is not signed by the delegate")
+	}
+	key, err := libtrust.FromCryptoPublicKey(crypto.PublicKey(cert.PublicKey))
+	if err != nil {
+		return signature.Claims{}, err
+	}
+	rawSig, err := signature.DecodeSegment(parts[2])
+	if err != nil {
+		return signature.Claims{}, signature.ErrInvalidToken
+	}
+	if err := key.Verify(strings.NewReader(strings.Join(parts[:2], ".")), header.Algorithm, rawSig); err != nil {
+		return signature.Claims{}, err
+	}
+
+	claims, err := signature.DecodeClaims(parts[1])
+	if err != nil {
+		return signature.Claims{}, err
+	}
+	if claims.IssuedAt < delegation.IssuedAt || claims.IssuedAt > delegation.Expiration {
+		return signature.Claims{}, errors.New("signature is not signed within the delegation validity")
+	}
+	if claims.Expiration != 0 && time.Now().Unix() > claims.Expiration {
+		return signature.Claims{}, fmt.Errorf("content expired: %d", claims.Expiration)
+	}
+	if len(claims.References) == 0 {
+		return signature.Claims{}, errors.New("signature has no reference to check against the delegation scope")
+	}
+	for _, reference := range claims.References {
+		if matched, _ := path.Match(delegation.Scope, repositoryName(reference)); !matched {
+			return signature.Claims{}, fmt.Errorf("reference %s is out of the delegation scope %s", reference, delegation.Scope)
+		}
+	}
+	return claims, nil
+}
+
+// verifyDelegationToken verifies the token and returns its claims if it is
+// valid at present
+func verifyDelegationToken(token DelegationToken, rootVerifier signature.Verifier) (DelegationClaims, error) {
+	parts := strings.Split(string(token), ".")
+	if len(parts) != 3 {
+		return DelegationClaims{}, signature.ErrInvalidToken
+	}
+	rawHeader, err := signature.DecodeSegment(parts[0])
+	if err != nil {
+		return DelegationClaims{}, signature.ErrInvalidToken
+	}
+	var header Header
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return DelegationClaims{}, signature.ErrInvalidToken
+	}
+	if header.ContentType != DelegationContentType {
+		return DelegationClaims{}, fmt.Errorf("not a delegation token: content type %q", header.ContentType)
+	}
+	// the root verifier verifies signatures, which carry no content type
+	var params Parameters
+	if err := json.Unmarshal(rawHeader, &params); err != nil {
+		return DelegationClaims{}, signature.ErrInvalidToken
+	}
+	params.ContentType = ""
+	rawParams, err := json.MarshalCanonical(params)
+	if err != nil {
+		return DelegationClaims{}, err
+	}
+	header.Header.Raw = rawParams
+	sig, err := signature.DecodeSegment(parts[2])
+	if err != nil {
+		return DelegationClaims{}, signature.ErrInvalidToken
+	}
+	if err := rootVerifier.Verify(header.Header, strings.Join(parts[:2], "."), sig); err != nil {
+		return DelegationClaims{}, err
+	}
+
+	rawClaims, err := signature.DecodeSegment(parts[1])
+	if err != nil {
+		return DelegationClaims{}, signature.ErrInvalidToken
+	}
+	var claims DelegationClaims
+	if err := json.Unmarshal(rawClaims, &claims); err != nil {
+		return DelegationClaims{}, signature.ErrInvalidToken
+	}
+	now := time.Now().Unix()
+	if now < claims.IssuedAt || now > claims.Expiration {
+		return DelegationClaims{}, errors.New("delegation expired")
+	}
+	return claims, nil
+}
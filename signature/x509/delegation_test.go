@@ -0,0 +1,174 @@
+package x509_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/docker/go/canonical/json"
+	"github.com/docker/libtrust"
+	"github.com/notaryproject/notary/v2/signature"
+	x509nv2 "github.com/notaryproject/notary/v2/signature/x509"
+)
+
+// newTestDelegate creates a signer with a self-signed certificate valid for
+// registry.example.com
+func newTestDelegate(t *testing.T) (signature.Signer, *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "delegate"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"registry.example.com"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingKey, err := libtrust.FromCryptoPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := x509nv2.NewSigner(signingKey, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, cert
+}
+
+// signTestClaims signs claims for the reference with the signer
+func signTestClaims(t *testing.T, signer signature.Signer, reference string) []byte {
+	t.Helper()
+	claims, err := json.MarshalCanonical(signature.Claims{
+		Manifest: signature.Manifest{
+			Descriptor: signature.Descriptor{
+				MediaType: "application/vnd.oci.image.manifest.v1+json",
+				Digest:    "sha256:73c803930ea3ba1e54bc25c2bdc53edd0284c62ed651fe7b00369da519a3c333",
+				Size:      528,
+			},
+			References: []string{reference},
+		},
+		IssuedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, sig, err := signer.Sign(signature.EncodeSegment(claims))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []byte(signed + "." + signature.EncodeSegment(sig))
+}
+
+func TestVerifyWithDelegation(t *testing.T) {
+	rootSigner, rootCert := newTestDelegate(t)
+	roots := x509.NewCertPool()
+	roots.AddCert(rootCert)
+	rootVerifier, err := x509nv2.NewVerifier(nil, roots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delegateSigner, delegateCert := newTestDelegate(t)
+	_, otherCert := newTestDelegate(t)
+
+	issue := func(cert *x509.Certificate, scope string) x509nv2.DelegationToken {
+		token, err := x509nv2.IssueDelegationToken(cert, scope, time.Hour, rootSigner)
+		if err != nil {
+			t.Fatalf("IssueDelegationToken() error = %v", err)
+		}
+		return token
+	}
+	expired, err := x509nv2.SignDelegationClaims(x509nv2.DelegationClaims{
+		Scope:       "registry.example.com/team/*",
+		Subject:     delegateCert.Subject.String(),
+		Fingerprint: x509nv2.Fingerprint(delegateCert),
+		IssuedAt:    time.Now().Add(-2 * time.Hour).Unix(),
+		Expiration:  time.Now().Add(-time.Hour).Unix(),
+	}, rootSigner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reference := "registry.example.com/team/app:v1"
+
+	for _, tt := range []struct {
+		name    string
+		sig     []byte
+		token   x509nv2.DelegationToken
+		wantErr bool
+	}{
+		{
+			name:  "valid",
+			sig:   signTestClaims(t, delegateSigner, reference),
+			token: issue(delegateCert, "registry.example.com/team/*"),
+		},
+		{
+			name:    "expired token",
+			sig:     signTestClaims(t, delegateSigner, reference),
+			token:   expired,
+			wantErr: true,
+		},
+		{
+			name:    "out of scope",
+			sig:     signTestClaims(t, delegateSigner, "registry.example.com/other/app:v1"),
+			token:   issue(delegateCert, "registry.example.com/team/*"),
+			wantErr: true,
+		},
+		{
+			name:    "wrong fingerprint",
+			sig:     signTestClaims(t, delegateSigner, reference),
+			token:   issue(otherCert, "registry.example.com/team/*"),
+			wantErr: true,
+		},
+		{
+			name:    "signature as token",
+			sig:     signTestClaims(t, delegateSigner, reference),
+			token:   x509nv2.DelegationToken(signTestClaims(t, rootSigner, reference)),
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := x509nv2.VerifyWithDelegation(tt.sig, tt.token, rootVerifier)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyWithDelegation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && claims.References[0] != reference {
+				t.Errorf("VerifyWithDelegation() references = %v, want %v", claims.References, reference)
+			}
+		})
+	}
+}
+
+func TestDelegationTokenIsNotSignature(t *testing.T) {
+	rootSigner, rootCert := newTestDelegate(t)
+	roots := x509.NewCertPool()
+	roots.AddCert(rootCert)
+	_, delegateCert := newTestDelegate(t)
+	token, err := x509nv2.IssueDelegationToken(delegateCert, "registry.example.com/*", time.Hour, rootSigner)
+	if err != nil {
+		t.Fatalf("IssueDelegationToken() error = %v", err)
+	}
+
+	scheme := signature.NewScheme()
+	verifier, err := x509nv2.NewVerifier(nil, roots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheme.RegisterVerifier(verifier)
+	if _, err := scheme.Verify(string(token)); err == nil {
+		t.Error("Verify() accepted a delegation token as a signature")
+	}
+}
@@ -0,0 +1,75 @@
+package x509
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/docker/go/canonical/json"
+	"github.com/docker/libtrust"
+	"github.com/notaryproject/notary/v2/signature"
+)
+
+// EmbeddedKeyVerificationWarning is returned by VerifyEmbedded when the
+// signature is intact. The key embedded in a signature proves that the content
+// has not been altered since signing, but nothing about who signed it.
+type EmbeddedKeyVerificationWarning struct {
+	Claims signature.Claims
+}
+
+func (e *EmbeddedKeyVerificationWarning) Error() string {
+	return "signature verified with the embedded public key: signer identity not established"
+}
+
+// VerifyEmbedded verifies the signature with the public key embedded in its
+// payload rather than a trust store. It returns an
+// *EmbeddedKeyVerificationWarning with the verified claims if the signature
+// is intact, and any other error if it is not.
+func VerifyEmbedded(sig []byte) error {
+	parts := strings.Split(string(sig), ".")
+	if len(parts) != 3 {
+		return signature.ErrInvalidToken
+	}
+	rawHeader, err := signature.DecodeSegment(parts[0])
+	if err != nil {
+		return signature.ErrInvalidToken
+	}
+	var header Header
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return signature.ErrInvalidToken
+	}
+	if header.Type != Type {
+		return signature.ErrInvalidSignatureType
+	}
+	claims, err := signature.DecodeClaims(parts[1])
+	if err != nil {
+		return err
+	}
+	if claims.EmbeddedPublicKey == "" {
+		return errors.New("missing embedded public key")
+	}
+	der, err := base64.StdEncoding.DecodeString(claims.EmbeddedPublicKey)
+	if err != nil {
+		return err
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return err
+	}
+	key, err := libtrust.FromCryptoPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	rawSig, err := signature.DecodeSegment(parts[2])
+	if err != nil {
+		return signature.ErrInvalidToken
+	}
+	signed := strings.Join(parts[:2], ".")
+	if err := key.Verify(strings.NewReader(signed), header.Algorithm, rawSig); err != nil {
+		return err
+	}
+	return &EmbeddedKeyVerificationWarning{
+		Claims: claims,
+	}
+}
@@ -0,0 +1,5 @@
+package x509
+
+// SignDelegationClaims exposes signDelegationClaims to issue tokens with
+// arbitrary claims, e.g. expired ones.
+var SignDelegationClaims = signDelegationClaims
@@ -1,6 +1,13 @@
 package x509
 
-import "github.com/notaryproject/notary/v2/signature"
+import (
+	"crypto/x509"
+	"errors"
+	"strings"
+
+	"github.com/docker/go/canonical/json"
+	"github.com/notaryproject/notary/v2/signature"
+)
 
 // Header defines the signature header
 type Header struct {
@@ -10,7 +17,85 @@ type Header struct {
 
 // Parameters defines the signature parameters
 type Parameters struct {
-	Algorithm string   `json:"alg,omitempty"`
-	KeyID     string   `json:"kid,omitempty"`
-	X5c       [][]byte `json:"x5c,omitempty"`
+	Algorithm   string   `json:"alg,omitempty"`
+	KeyID       string   `json:"kid,omitempty"`
+	X5c         [][]byte `json:"x5c,omitempty"`
+	ContentType string   `json:"cty,omitempty"`
+}
+
+// SignerCommonName returns the common name of the signing certificate in the
+// x5c header of the signature, e.g. to list the signatures of an image.
+// The signature is NOT verified, so the result is for display only and must
+// not be used for security decisions.
+func SignerCommonName(sig []byte) (string, error) {
+	cert, err := signingCertificate(sig)
+	if err != nil {
+		return "", err
+	}
+	return cert.Subject.CommonName, nil
+}
+
+// SignerFingerprint returns the fingerprint of the signing certificate in the
+// x5c header of the signature, which identifies the signer across signatures.
+// The signature is NOT verified, so the result must not be used for security
+// decisions.
+func SignerFingerprint(sig []byte) (string, error) {
+	cert, err := signingCertificate(sig)
+	if err != nil {
+		return "", err
+	}
+	return Fingerprint(cert), nil
+}
+
+// CertificateChain returns the certificate chain in the x5c header of the
+// signature, leaf first. It is empty if the signing certificate is identified
+// by the kid header instead. The signature and the chain are NOT verified.
+func CertificateChain(sig []byte) ([]*x509.Certificate, error) {
+	header, err := unverifiedHeader(sig)
+	if err != nil {
+		return nil, err
+	}
+	certs := make([]*x509.Certificate, 0, len(header.X5c))
+	for _, certBytes := range header.X5c {
+		cert, err := x509.ParseCertificate(certBytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// signingCertificate parses the leaf certificate in the x5c header without
+// verifying the signature.
+func signingCertificate(sig []byte) (*x509.Certificate, error) {
+	header, err := unverifiedHeader(sig)
+	if err != nil {
+		return nil, err
+	}
+	if len(header.X5c) == 0 {
+		return nil, errors.New("missing signing certificate")
+	}
+	return x509.ParseCertificate(header.X5c[0])
+}
+
+// unverifiedHeader decodes the x509 header of the signature without verifying
+// the signature.
+func unverifiedHeader(sig []byte) (Header, error) {
+	parts := strings.Split(string(sig), ".")
+	if len(parts) != 3 {
+		return Header{}, signature.ErrInvalidToken
+	}
+	rawHeader, err := signature.DecodeSegment(parts[0])
+	if err != nil {
+		return Header{}, signature.ErrInvalidToken
+	}
+	var header Header
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return Header{}, signature.ErrInvalidToken
+	}
+	if header.Type != Type {
+		return Header{}, signature.ErrInvalidSignatureType
+	}
+	return header, nil
 }
@@ -0,0 +1,123 @@
+package x509
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+
+	"github.com/docker/libtrust"
+	"github.com/notaryproject/notary/v2/signature"
+)
+
+// LoadSignerFromPEMFile creates a signer from a PEM file containing a PKCS #8,
+// PKCS #1 or SEC 1 private key, optionally bundled with its certificate chain.
+// If password is not nil, the private key is decrypted with it, either as an
+// encrypted PKCS #8 private key using PBES2, or as a legacy encrypted PEM block.
+// The leaf certificate is returned if present.
+func LoadSignerFromPEMFile(path string, password []byte) (signature.Signer, *x509.Certificate, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var key libtrust.PrivateKey
+	var certs []*x509.Certificate
+	for block, rest := pem.Decode(raw); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type == "CERTIFICATE" {
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, nil, err
+			}
+			certs = append(certs, cert)
+			continue
+		}
+		if key != nil {
+			return nil, nil, errors.New("multiple private keys found")
+		}
+		if key, err = parsePrivateKeyBlock(block, password); err != nil {
+			return nil, nil, err
+		}
+	}
+	if key == nil {
+		return nil, nil, errors.New("no private key found")
+	}
+
+	signer, err := NewSigner(key, certs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(certs) == 0 {
+		return signer, nil, nil
+	}
+	return signer, certs[0], nil
+}
+
+// LoadCertificateChainFromPEMFile reads the certificate chain from a PEM file,
+// with the leaf certificate first. Private keys bundled in the file are
+// skipped, so the file passed to LoadSignerFromPEMFile can be used as well.
+func LoadCertificateChainFromPEMFile(path string) ([]*x509.Certificate, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var certs []*x509.Certificate
+	for block, rest := pem.Decode(raw); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate found")
+	}
+	return certs, nil
+}
+
+func parsePrivateKeyBlock(block *pem.Block, password []byte) (libtrust.PrivateKey, error) {
+	der := block.Bytes
+	if x509.IsEncryptedPEMBlock(block) {
+		if password == nil {
+			return nil, errors.New("private key is encrypted")
+		}
+		var err error
+		if der, err = x509.DecryptPEMBlock(block, password); err != nil {
+			return nil, err
+		}
+	}
+
+	var key crypto.PrivateKey
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(der)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(der)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(der)
+	case "ENCRYPTED PRIVATE KEY":
+		if password == nil {
+			return nil, errors.New("private key is encrypted")
+		}
+		if der, err = decryptPKCS8PrivateKey(der, password); err != nil {
+			return nil, err
+		}
+		key, err = x509.ParsePKCS8PrivateKey(der)
+	default:
+		return nil, errors.New("unsupported PEM block type: " + block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := key.(ed25519.PrivateKey); ok {
+		return nil, errors.New("Ed25519 private keys are not supported")
+	}
+	return libtrust.FromCryptoPrivateKey(key)
+}
@@ -0,0 +1,81 @@
+package x509_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/notaryproject/notary/v2/signature"
+	x509nv2 "github.com/notaryproject/notary/v2/signature/x509"
+)
+
+func TestLoadSignerFromPEMFile(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		file     string
+		password []byte
+		wantCert bool
+		wantErr  bool
+	}{
+		{name: "PBES2 AES-256 HMAC-SHA256", file: "key_aes256_sha256.pem", password: []byte("notary")},
+		{name: "PBES2 AES-128 HMAC-SHA1", file: "key_aes128_sha1.pem", password: []byte("notary")},
+		{name: "bundled certificate", file: "bundle.pem", password: []byte("notary"), wantCert: true},
+		{name: "wrong password", file: "key_aes256_sha256.pem", password: []byte("wrong"), wantErr: true},
+		{name: "missing password", file: "key_aes256_sha256.pem", wantErr: true},
+		{name: "no private key", file: "cert.pem", wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, cert, err := x509nv2.LoadSignerFromPEMFile(filepath.Join("testdata", tt.file), tt.password)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("LoadSignerFromPEMFile() succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadSignerFromPEMFile() error = %v", err)
+			}
+			if (cert != nil) != tt.wantCert {
+				t.Fatalf("LoadSignerFromPEMFile() certificate = %v, want certificate %v", cert, tt.wantCert)
+			}
+
+			scheme := signature.NewScheme()
+			scheme.RegisterSigner("", signer)
+			if _, err := scheme.Sign("", signature.Claims{}); err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadCertificateChainFromPEMFile(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		file    string
+		want    int
+		wantErr bool
+	}{
+		{name: "certificate", file: "cert.pem", want: 1},
+		{name: "bundled with private key", file: "bundle.pem", want: 1},
+		{name: "no certificate", file: "key_aes128_sha1.pem", wantErr: true},
+		{name: "missing file", file: "missing.pem", wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			certs, err := x509nv2.LoadCertificateChainFromPEMFile(filepath.Join("testdata", tt.file))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("LoadCertificateChainFromPEMFile() succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadCertificateChainFromPEMFile() error = %v", err)
+			}
+			if len(certs) != tt.want {
+				t.Fatalf("LoadCertificateChainFromPEMFile() = %d certificates, want %d", len(certs), tt.want)
+			}
+			if certs[0].Subject.CommonName != "notary test" {
+				t.Fatalf("LoadCertificateChainFromPEMFile() leaf = %q, want %q", certs[0].Subject.CommonName, "notary test")
+			}
+		})
+	}
+}
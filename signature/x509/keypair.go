@@ -0,0 +1,92 @@
+package x509
+
+import (
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"strings"
+
+	"github.com/docker/go/canonical/json"
+	"github.com/docker/libtrust"
+	"github.com/notaryproject/notary/v2/signature"
+)
+
+// KeyPair bundles a signer with the verifier and the certificate of its key,
+// so that they are not mixed up.
+type KeyPair struct {
+	Signer      signature.Signer
+	Verifier    signature.Verifier
+	Certificate *x509.Certificate
+
+	// Chain is the certificate chain, starting with Certificate.
+	Chain []*x509.Certificate
+}
+
+// NewKeyPairFromPEM creates a key pair from the PEM encoded certificate chain
+// and private key files. The verifier trusts the certificates in the chain.
+func NewKeyPairFromPEM(certPath, keyPath string) (KeyPair, error) {
+	key, err := ReadPrivateKeyFile(keyPath)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	certs, err := ReadCertificateFile(certPath)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	if len(certs) == 0 {
+		return KeyPair{}, errors.New("no certificate found")
+	}
+	signer, err := NewSigner(key, certs)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	verifier, err := NewVerifier(certs, nil)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	return KeyPair{
+		Signer:      signer,
+		Verifier:    verifier,
+		Certificate: certs[0],
+		Chain:       certs,
+	}, nil
+}
+
+// Valid checks that the signer signs with the key of the certificate, and
+// that the verifier accepts its signatures.
+func (p KeyPair) Valid() error {
+	if p.Signer == nil || p.Certificate == nil {
+		return errors.New("incomplete key pair")
+	}
+
+	// sign empty claims as a probe
+	signed, sig, err := p.Signer.Sign(signature.EncodeSegment([]byte("{}")))
+	if err != nil {
+		return err
+	}
+	parts := strings.Split(signed, ".")
+	if len(parts) != 2 {
+		return errors.New("invalid signed content")
+	}
+	rawHeader, err := signature.DecodeSegment(parts[0])
+	if err != nil {
+		return err
+	}
+	var header Header
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return err
+	}
+	header.Raw = rawHeader
+
+	key, err := libtrust.FromCryptoPublicKey(crypto.PublicKey(p.Certificate.PublicKey))
+	if err != nil {
+		return err
+	}
+	if err := key.Verify(strings.NewReader(signed), header.Algorithm, sig); err != nil {
+		return errors.New("key and certificate mismatch")
+	}
+	if p.Verifier != nil {
+		return p.Verifier.Verify(header.Header, signed, sig)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package x509_test
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	x509nv2 "github.com/notaryproject/notary/v2/signature/x509"
+)
+
+// writeTestKeyPair writes a self-signed certificate and its PKCS #8 key to PEM
+// files, and returns their paths
+func writeTestKeyPair(t *testing.T, dir, name string) (string, string) {
+	t.Helper()
+	key, cert := newTestCertificate(t, name, nil, nil)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPath := filepath.Join(dir, name+".crt")
+	keyPath := filepath.Join(dir, name+".key")
+	if err := ioutil.WriteFile(certPath, []byte(encodeCertificates(cert)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certPath, keyPath
+}
+
+func TestNewKeyPairFromPEM(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath := writeTestKeyPair(t, dir, "alice")
+	pair, err := x509nv2.NewKeyPairFromPEM(certPath, keyPath)
+	if err != nil {
+		t.Fatalf("NewKeyPairFromPEM() error = %v", err)
+	}
+	if pair.Certificate.Subject.CommonName != "alice" || len(pair.Chain) != 1 || pair.Chain[0] != pair.Certificate {
+		t.Errorf("NewKeyPairFromPEM() = %+v, want the certificate of alice", pair)
+	}
+	if err := pair.Valid(); err != nil {
+		t.Errorf("Valid() error = %v", err)
+	}
+}
+
+func TestNewKeyPairFromPEMError(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath := writeTestKeyPair(t, dir, "alice")
+	emptyPath := filepath.Join(dir, "empty.pem")
+	if err := ioutil.WriteFile(emptyPath, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name     string
+		certPath string
+		keyPath  string
+	}{
+		{name: "missing key", certPath: certPath, keyPath: filepath.Join(dir, "missing.key")},
+		{name: "missing certificate", certPath: filepath.Join(dir, "missing.crt"), keyPath: keyPath},
+		{name: "no certificate", certPath: emptyPath, keyPath: keyPath},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := x509nv2.NewKeyPairFromPEM(tt.certPath, tt.keyPath); err == nil {
+				t.Error("NewKeyPairFromPEM() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestKeyPairValidMismatch(t *testing.T) {
+	dir := t.TempDir()
+	alice, err := x509nv2.NewKeyPairFromPEM(writeTestKeyPair(t, dir, "alice"))
+	if err != nil {
+		t.Fatalf("NewKeyPairFromPEM() error = %v", err)
+	}
+	bob, err := x509nv2.NewKeyPairFromPEM(writeTestKeyPair(t, dir, "bob"))
+	if err != nil {
+		t.Fatalf("NewKeyPairFromPEM() error = %v", err)
+	}
+
+	mixed := x509nv2.KeyPair{
+		Signer:      alice.Signer,
+		Certificate: bob.Certificate,
+	}
+	if err := mixed.Valid(); err == nil || !strings.Contains(err.Error(), "mismatch") {
+		t.Errorf("Valid() of a mixed key pair error = %v, want a mismatch", err)
+	}
+	if err := (x509nv2.KeyPair{Signer: alice.Signer}).Valid(); err == nil {
+		t.Error("Valid() of an incomplete key pair error = nil, want an error")
+	}
+
+	// the verifier of another key pair does not trust the signer
+	alice.Verifier = bob.Verifier
+	if err := alice.Valid(); err == nil {
+		t.Error("Valid() with the verifier of another key pair error = nil, want an error")
+	}
+}
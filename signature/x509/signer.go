@@ -68,7 +68,13 @@ func NewSigner(key libtrust.PrivateKey, certs []*x509.Certificate) (signature.Si
 }
 
 func (s *signer) Sign(claims string) (string, []byte, error) {
-	if s.cert != nil {
+	return s.sign(claims, "")
+}
+
+// sign signs the claims with the content type in the header, which marks
+// tokens other than signatures
+func (s *signer) sign(claims, contentType string) (string, []byte, error) {
+	if contentType == "" && s.cert != nil {
 		if err := verifyReferences(claims, s.cert); err != nil {
 			return "", nil, err
 		}
@@ -85,7 +91,8 @@ func (s *signer) Sign(claims string) (string, []byte, error) {
 			Type: Type,
 		},
 		Parameters: Parameters{
-			Algorithm: alg,
+			Algorithm:   alg,
+			ContentType: contentType,
 		},
 	}
 	if s.cert != nil {
@@ -0,0 +1,20 @@
+package x509
+
+import (
+	"github.com/notaryproject/notary/v2/signature"
+)
+
+// EstimateSignatureSize returns the size of the signature blob the signer
+// produces for the JSON encoded claims payload, e.g. to check it against the
+// limits of the registry before signing and uploading.
+// The payload is signed once and the resulting signature is measured, which
+// is exact for fixed size signature algorithms like ECDSA and RSA as long as
+// the actual claims have the same size. The certificate chain of the signer
+// is part of the signature header, and therefore is included.
+func EstimateSignatureSize(s signature.Signer, payload []byte) (int64, error) {
+	signed, sig, err := s.Sign(signature.EncodeSegment(payload))
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(signed) + len(".") + len(signature.EncodeSegment(sig))), nil
+}
@@ -0,0 +1,100 @@
+package x509_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/docker/go/canonical/json"
+	"github.com/docker/libtrust"
+	"github.com/notaryproject/notary/v2/signature"
+	x509nv2 "github.com/notaryproject/notary/v2/signature/x509"
+)
+
+// newTestCertificate generates a P-256 key with a certificate issued by the
+// parent, or a self-signed certificate if parent is nil. Issued certificates
+// are valid for registry.example.com.
+func newTestCertificate(t *testing.T, commonName string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*ecdsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  parent == nil,
+		BasicConstraintsValid: true,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	} else {
+		template.DNSNames = []string{"registry.example.com"}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, cert
+}
+
+// newTestSigner creates a signer with a leaf certificate issued by a root
+func newTestSigner(t *testing.T) (signature.Signer, []*x509.Certificate) {
+	t.Helper()
+	rootKey, root := newTestCertificate(t, "root", nil, nil)
+	key, leaf := newTestCertificate(t, "leaf", root, rootKey)
+	signingKey, err := libtrust.FromCryptoPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certs := []*x509.Certificate{leaf, root}
+	signer, err := x509nv2.NewSigner(signingKey, certs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, certs
+}
+
+func TestEstimateSignatureSize(t *testing.T) {
+	signer, _ := newTestSigner(t)
+	claims := signature.Claims{
+		Manifest: signature.Manifest{
+			Descriptor: signature.Descriptor{
+				MediaType: "application/vnd.oci.image.manifest.v1+json",
+				Digest:    "sha256:73c803930ea3ba1e54bc25c2bdc53edd0284c62ed651fe7b00369da519a3c333",
+				Size:      528,
+			},
+		},
+		IssuedAt: time.Now().Unix(),
+	}
+	payload, err := json.MarshalCanonical(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := x509nv2.EstimateSignatureSize(signer, payload)
+	if err != nil {
+		t.Fatalf("EstimateSignatureSize() error = %v", err)
+	}
+
+	scheme := signature.NewScheme()
+	scheme.RegisterSigner("", signer)
+	token, err := scheme.Sign("", claims)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if want := int64(len(token)); got != want {
+		t.Fatalf("EstimateSignatureSize() = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,274 @@
+package x509
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// object identifiers used by RFC 3161 timestamp tokens
+var (
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+
+	oidSHA1   = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+
+	oidRSA           = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidSHA256WithRSA = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}
+	oidSHA384WithRSA = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 12}
+	oidSHA512WithRSA = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 13}
+	oidECPublicKey   = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidECDSAWithSHA2 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3}
+)
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	EncapContentInfo encapsulatedContentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+type encapsulatedContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     []byte `asn1:"explicit,tag:0"`
+}
+
+type signerInfo struct {
+	Version            int
+	SID                asn1.RawValue
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+	UnsignedAttrs      asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type issuerAndSerialNumber struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values []asn1.RawValue `asn1:"set"`
+}
+
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint messageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time `asn1:"generalized"`
+}
+
+type messageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+// VerifyTimestamp verifies the RFC 3161 timestamp token in the base64 encoded
+// annotation, and returns the time at which it asserts the signature existed.
+// The token must be issued for the signature, and signed by a TSA certificate
+// chaining to root.
+func VerifyTimestamp(ann string, signature []byte, root *x509.Certificate) (time.Time, error) {
+	token, err := base64.StdEncoding.DecodeString(ann)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var content contentInfo
+	if rest, err := asn1.Unmarshal(token, &content); err != nil {
+		return time.Time{}, err
+	} else if len(rest) > 0 {
+		return time.Time{}, errors.New("trailing data after timestamp token")
+	}
+	if !content.ContentType.Equal(oidSignedData) {
+		return time.Time{}, errors.New("timestamp token is not signed data")
+	}
+	var signed signedData
+	if _, err := asn1.Unmarshal(content.Content.Bytes, &signed); err != nil {
+		return time.Time{}, err
+	}
+	if !signed.EncapContentInfo.EContentType.Equal(oidTSTInfo) {
+		return time.Time{}, errors.New("timestamp token does not contain TSTInfo")
+	}
+	if len(signed.SignerInfos) != 1 {
+		return time.Time{}, errors.New("timestamp token must have exactly one signer")
+	}
+	signer := signed.SignerInfos[0]
+	certs, err := x509.ParseCertificates(signed.Certificates.Bytes)
+	if err != nil {
+		return time.Time{}, err
+	}
+	cert, err := findSignerCertificate(certs, signer.SID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// verify the signature of the TSA
+	if err := verifySignerInfo(signer, cert, signed.EncapContentInfo.EContent); err != nil {
+		return time.Time{}, err
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range certs {
+		intermediates.AddCert(c)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+
+	var info tstInfo
+	if _, err := asn1.Unmarshal(signed.EncapContentInfo.EContent, &info); err != nil {
+		return time.Time{}, err
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Intermediates: intermediates,
+		Roots:         roots,
+		CurrentTime:   info.GenTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+	}); err != nil {
+		return time.Time{}, err
+	}
+
+	// verify that the timestamp is issued for the signature
+	hash, err := hashFromOID(info.MessageImprint.HashAlgorithm.Algorithm)
+	if err != nil {
+		return time.Time{}, err
+	}
+	h := hash.New()
+	h.Write(signature)
+	if !bytes.Equal(h.Sum(nil), info.MessageImprint.HashedMessage) {
+		return time.Time{}, errors.New("timestamp token is not issued for the signature")
+	}
+	return info.GenTime, nil
+}
+
+// findSignerCertificate finds the certificate identified by the signer
+// identifier, either by issuer and serial number or by subject key identifier.
+func findSignerCertificate(certs []*x509.Certificate, sid asn1.RawValue) (*x509.Certificate, error) {
+	if sid.Class == asn1.ClassContextSpecific && sid.Tag == 0 {
+		for _, cert := range certs {
+			if bytes.Equal(cert.SubjectKeyId, sid.Bytes) {
+				return cert, nil
+			}
+		}
+		return nil, errors.New("timestamp signer certificate not found")
+	}
+	var ias issuerAndSerialNumber
+	if _, err := asn1.Unmarshal(sid.FullBytes, &ias); err != nil {
+		return nil, err
+	}
+	for _, cert := range certs {
+		if cert.SerialNumber.Cmp(ias.SerialNumber) == 0 && bytes.Equal(cert.RawIssuer, ias.Issuer.FullBytes) {
+			return cert, nil
+		}
+	}
+	return nil, errors.New("timestamp signer certificate not found")
+}
+
+// verifySignerInfo verifies the signature over the signed attributes, which
+// must bind the content by its digest.
+func verifySignerInfo(signer signerInfo, cert *x509.Certificate, content []byte) error {
+	if len(signer.SignedAttrs.Bytes) == 0 {
+		return errors.New("timestamp token has no signed attributes")
+	}
+	hash, err := hashFromOID(signer.DigestAlgorithm.Algorithm)
+	if err != nil {
+		return err
+	}
+
+	var attrs []attribute
+	if _, err := asn1.UnmarshalWithParams(signer.SignedAttrs.FullBytes, &attrs, "set,tag:0"); err != nil {
+		return err
+	}
+	var digest []byte
+	var contentType asn1.ObjectIdentifier
+	for _, attr := range attrs {
+		if len(attr.Values) != 1 {
+			continue
+		}
+		switch {
+		case attr.Type.Equal(oidMessageDigest):
+			if _, err := asn1.Unmarshal(attr.Values[0].FullBytes, &digest); err != nil {
+				return err
+			}
+		case attr.Type.Equal(oidContentType):
+			if _, err := asn1.Unmarshal(attr.Values[0].FullBytes, &contentType); err != nil {
+				return err
+			}
+		}
+	}
+	if !contentType.Equal(oidTSTInfo) {
+		return errors.New("timestamp token content type mismatch")
+	}
+	h := hash.New()
+	h.Write(content)
+	if !bytes.Equal(h.Sum(nil), digest) {
+		return errors.New("timestamp token content digest mismatch")
+	}
+
+	algorithm, err := signatureAlgorithm(signer.SignatureAlgorithm.Algorithm, hash)
+	if err != nil {
+		return err
+	}
+	// the signature is computed over the DER encoding of the signed attributes
+	// with the SET OF tag instead of the implicit tag
+	signedAttrs := append([]byte{0x31}, signer.SignedAttrs.FullBytes[1:]...)
+	return cert.CheckSignature(algorithm, signedAttrs, signer.Signature)
+}
+
+func hashFromOID(oid asn1.ObjectIdentifier) (crypto.Hash, error) {
+	switch {
+	case oid.Equal(oidSHA1):
+		return crypto.SHA1, nil
+	case oid.Equal(oidSHA256):
+		return crypto.SHA256, nil
+	case oid.Equal(oidSHA384):
+		return crypto.SHA384, nil
+	case oid.Equal(oidSHA512):
+		return crypto.SHA512, nil
+	default:
+		return 0, fmt.Errorf("unsupported hash algorithm: %v", oid)
+	}
+}
+
+func signatureAlgorithm(oid asn1.ObjectIdentifier, hash crypto.Hash) (x509.SignatureAlgorithm, error) {
+	rsa := map[crypto.Hash]x509.SignatureAlgorithm{
+		crypto.SHA1:   x509.SHA1WithRSA,
+		crypto.SHA256: x509.SHA256WithRSA,
+		crypto.SHA384: x509.SHA384WithRSA,
+		crypto.SHA512: x509.SHA512WithRSA,
+	}
+	ecdsa := map[crypto.Hash]x509.SignatureAlgorithm{
+		crypto.SHA1:   x509.ECDSAWithSHA1,
+		crypto.SHA256: x509.ECDSAWithSHA256,
+		crypto.SHA384: x509.ECDSAWithSHA384,
+		crypto.SHA512: x509.ECDSAWithSHA512,
+	}
+	switch {
+	case oid.Equal(oidRSA), oid.Equal(oidSHA256WithRSA), oid.Equal(oidSHA384WithRSA), oid.Equal(oidSHA512WithRSA):
+		return rsa[hash], nil
+	case oid.Equal(oidECPublicKey), len(oid) == len(oidECDSAWithSHA2)+1 && oid[:len(oidECDSAWithSHA2)].Equal(oidECDSAWithSHA2):
+		return ecdsa[hash], nil
+	default:
+		return x509.UnknownSignatureAlgorithm, fmt.Errorf("unsupported signature algorithm: %v", oid)
+	}
+}
@@ -0,0 +1,214 @@
+package x509_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+
+	x509nv2 "github.com/notaryproject/notary/v2/signature/x509"
+)
+
+var (
+	oidSignedData      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidContentType     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidSHA256          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidECDSAWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+)
+
+type testAttribute struct {
+	Type   asn1.ObjectIdentifier
+	Values []asn1.RawValue `asn1:"set"`
+}
+
+type testSignerInfo struct {
+	Version            int
+	SID                asn1.RawValue
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+}
+
+type testSignedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	EncapContentInfo struct {
+		EContentType asn1.ObjectIdentifier
+		EContent     []byte `asn1:"explicit,tag:0"`
+	}
+	Certificates asn1.RawValue
+	SignerInfos  []testSignerInfo `asn1:"set"`
+}
+
+type testTSTInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint struct {
+		HashAlgorithm pkix.AlgorithmIdentifier
+		HashedMessage []byte
+	}
+	SerialNumber *big.Int
+	GenTime      time.Time `asn1:"generalized"`
+}
+
+// newTestTimestamp issues a base64 encoded RFC 3161 timestamp token for the
+// signature at genTime, signed by the TSA key and carrying the certificates
+func newTestTimestamp(t *testing.T, signature []byte, genTime time.Time, key *ecdsa.PrivateKey, certs ...*x509.Certificate) string {
+	t.Helper()
+	marshal := func(v interface{}) []byte {
+		b, err := asn1.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	sha256Algorithm := pkix.AlgorithmIdentifier{Algorithm: oidSHA256}
+
+	info := testTSTInfo{
+		Version:      1,
+		Policy:       asn1.ObjectIdentifier{1, 2, 3},
+		SerialNumber: big.NewInt(1),
+		GenTime:      genTime,
+	}
+	info.MessageImprint.HashAlgorithm = sha256Algorithm
+	imprint := sha256.Sum256(signature)
+	info.MessageImprint.HashedMessage = imprint[:]
+	content := marshal(info)
+
+	// the signature is computed over the signed attributes as a SET OF, which
+	// are then embedded with the implicit tag
+	contentDigest := sha256.Sum256(content)
+	attrs := marshal([]testAttribute{
+		{Type: oidContentType, Values: []asn1.RawValue{{FullBytes: marshal(oidTSTInfo)}}},
+		{Type: oidMessageDigest, Values: []asn1.RawValue{{FullBytes: marshal(contentDigest[:])}}},
+	})
+	attrs[0] = 0x31
+	attrsDigest := sha256.Sum256(attrs)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, attrsDigest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signedAttrs := append([]byte{0xa0}, attrs[1:]...)
+
+	var rawCerts []byte
+	for _, cert := range certs {
+		rawCerts = append(rawCerts, cert.Raw...)
+	}
+	signed := testSignedData{
+		Version:          3,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{sha256Algorithm},
+		Certificates: asn1.RawValue{
+			Class:      asn1.ClassContextSpecific,
+			Tag:        0,
+			IsCompound: true,
+			Bytes:      rawCerts,
+		},
+		SignerInfos: []testSignerInfo{{
+			Version: 1,
+			SID: asn1.RawValue{FullBytes: marshal(struct {
+				Issuer       asn1.RawValue
+				SerialNumber *big.Int
+			}{asn1.RawValue{FullBytes: certs[0].RawIssuer}, certs[0].SerialNumber})},
+			DigestAlgorithm:    sha256Algorithm,
+			SignedAttrs:        asn1.RawValue{FullBytes: signedAttrs},
+			SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidECDSAWithSHA256},
+			Signature:          sig,
+		}},
+	}
+	signed.EncapContentInfo.EContentType = oidTSTInfo
+	signed.EncapContentInfo.EContent = content
+
+	token := marshal(struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue
+	}{oidSignedData, asn1.RawValue{
+		Class:      asn1.ClassContextSpecific,
+		Tag:        0,
+		IsCompound: true,
+		Bytes:      marshal(signed),
+	}})
+	return base64.StdEncoding.EncodeToString(token)
+}
+
+func TestVerifyTimestamp(t *testing.T) {
+	rootKey, root := newTestCertificate(t, "root", nil, nil)
+	tsaKey, tsa := newTestCertificate(t, "tsa", root, rootKey)
+	signature := []byte("signature")
+	genTime := time.Now().UTC().Truncate(time.Second)
+
+	got, err := x509nv2.VerifyTimestamp(newTestTimestamp(t, signature, genTime, tsaKey, tsa), signature, root)
+	if err != nil {
+		t.Fatalf("VerifyTimestamp() error = %v", err)
+	}
+	if !got.Equal(genTime) {
+		t.Errorf("VerifyTimestamp() = %v, want %v", got, genTime)
+	}
+}
+
+func TestVerifyTimestampErrors(t *testing.T) {
+	rootKey, root := newTestCertificate(t, "root", nil, nil)
+	tsaKey, tsa := newTestCertificate(t, "tsa", root, rootKey)
+	_, otherRoot := newTestCertificate(t, "other root", nil, nil)
+	otherKey, _ := newTestCertificate(t, "other", root, rootKey)
+	signature := []byte("signature")
+	genTime := time.Now().UTC().Truncate(time.Second)
+
+	for _, tt := range []struct {
+		name      string
+		ann       string
+		signature []byte
+		root      *x509.Certificate
+	}{
+		{
+			name:      "not base64",
+			ann:       "!",
+			signature: signature,
+			root:      root,
+		},
+		{
+			name:      "not a token",
+			ann:       base64.StdEncoding.EncodeToString([]byte("token")),
+			signature: signature,
+			root:      root,
+		},
+		{
+			name:      "another signature",
+			ann:       newTestTimestamp(t, signature, genTime, tsaKey, tsa),
+			signature: []byte("another signature"),
+			root:      root,
+		},
+		{
+			name:      "untrusted TSA",
+			ann:       newTestTimestamp(t, signature, genTime, tsaKey, tsa),
+			signature: signature,
+			root:      otherRoot,
+		},
+		{
+			name:      "signed by another key",
+			ann:       newTestTimestamp(t, signature, genTime, otherKey, tsa),
+			signature: signature,
+			root:      root,
+		},
+		{
+			name:      "outside the TSA validity",
+			ann:       newTestTimestamp(t, signature, genTime.Add(24*time.Hour), tsaKey, tsa),
+			signature: signature,
+			root:      root,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := x509nv2.VerifyTimestamp(tt.ann, tt.signature, tt.root); err == nil {
+				t.Error("VerifyTimestamp() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package x509
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/docker/go/canonical/json"
+	"github.com/docker/libtrust"
+	"github.com/notaryproject/notary/v2/signature"
+)
+
+// SignerMismatchError is returned when an image is signed by a certificate
+// other than the one trusted on first use.
+type SignerMismatchError struct {
+	Image    string
+	Expected string
+	Actual   string
+}
+
+func (e *SignerMismatchError) Error() string {
+	return "signer of " + e.Image + " changed: expect " + e.Expected + ": got " + e.Actual
+}
+
+// TOFUStore records the fingerprint of the certificate an image is first seen
+// signed with, as the trust anchor of that image from then on.
+type TOFUStore struct {
+	path string
+
+	mu      sync.Mutex
+	anchors map[string]string
+}
+
+// NewTOFUStore creates a trust on first use store persisted to the JSON file at
+// path. The file is created if it does not exist.
+func NewTOFUStore(path string) (*TOFUStore, error) {
+	s := &TOFUStore{
+		path:    path,
+		anchors: make(map[string]string),
+	}
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &s.anchors); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Trust records fingerprint as the trust anchor of image if image is seen for
+// the first time, in which case firstUse is true. Otherwise, it returns
+// SignerMismatchError if fingerprint is not the recorded one.
+func (s *TOFUStore) Trust(image, fingerprint string) (firstUse bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if anchor, found := s.anchors[image]; found {
+		if anchor != fingerprint {
+			return false, &SignerMismatchError{
+				Image:    image,
+				Expected: anchor,
+				Actual:   fingerprint,
+			}
+		}
+		return false, nil
+	}
+	s.anchors[image] = fingerprint
+	if err := s.save(); err != nil {
+		// the anchor is not trusted until persisted
+		delete(s.anchors, image)
+		return false, err
+	}
+	return true, nil
+}
+
+// save persists the trust anchors. The caller must hold the lock.
+func (s *TOFUStore) save() error {
+	raw, err := json.Marshal(s.anchors)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// Fingerprint returns the hex encoded SHA-256 fingerprint of the certificate
+func Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+type tofuVerifier struct {
+	store *TOFUStore
+}
+
+// NewTOFUVerifier creates a verifier bootstrapping trust on first use.
+// The signing certificate in the x5c header is not chained to any root, but is
+// pinned for each image in the signed references on its first verification,
+// and every later signature of those images must be made with the same
+// certificate. A warning is logged on each first use, since the first
+// signature seen is trusted blindly.
+func NewTOFUVerifier(store *TOFUStore) signature.Verifier {
+	return &tofuVerifier{
+		store: store,
+	}
+}
+
+func (v *tofuVerifier) Type() string {
+	return Type
+}
+
+func (v *tofuVerifier) Verify(header signature.Header, signed string, sig []byte) error {
+	if header.Type != Type {
+		return signature.ErrInvalidSignatureType
+	}
+	var params Parameters
+	if err := json.Unmarshal(header.Raw, &params); err != nil {
+		return err
+	}
+	if len(params.X5c) == 0 {
+		return errors.New("missing signing certificate")
+	}
+	cert, err := x509.ParseCertificate(params.X5c[0])
+	if err != nil {
+		return err
+	}
+	key, err := libtrust.FromCryptoPublicKey(crypto.PublicKey(cert.PublicKey))
+	if err != nil {
+		return err
+	}
+	if err := key.Verify(strings.NewReader(signed), params.Algorithm, sig); err != nil {
+		return err
+	}
+
+	parts := strings.Split(signed, ".")
+	if len(parts) != 2 {
+		return errors.New("invalid signed content")
+	}
+	claims, err := signature.DecodeClaims(parts[1])
+	if err != nil {
+		return err
+	}
+	if len(claims.Manifest.References) == 0 {
+		return errors.New("trust on first use requires signed references")
+	}
+
+	fingerprint := Fingerprint(cert)
+	for _, reference := range claims.Manifest.References {
+		image := repositoryName(reference)
+		firstUse, err := v.store.Trust(image, fingerprint)
+		if err != nil {
+			return err
+		}
+		if firstUse {
+			log.Printf("WARNING: trust on first use: trusting certificate %s for %s", fingerprint, image)
+		}
+	}
+	return nil
+}
+
+// repositoryName strips the tag and the digest from the reference
+func repositoryName(reference string) string {
+	if i := strings.Index(reference, "@"); i >= 0 {
+		reference = reference[:i]
+	}
+	if i := strings.LastIndex(reference, ":"); i > strings.LastIndex(reference, "/") {
+		reference = reference[:i]
+	}
+	return reference
+}
@@ -0,0 +1,79 @@
+package x509_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	x509nv2 "github.com/notaryproject/notary/v2/signature/x509"
+)
+
+func TestTOFUStoreTrust(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tofu.json")
+	store, err := x509nv2.NewTOFUStore(path)
+	if err != nil {
+		t.Fatalf("NewTOFUStore() error = %v", err)
+	}
+
+	firstUse, err := store.Trust("registry.example.com/app", "alice")
+	if err != nil || !firstUse {
+		t.Fatalf("Trust() = %v, %v, want first use", firstUse, err)
+	}
+	firstUse, err = store.Trust("registry.example.com/app", "alice")
+	if err != nil || firstUse {
+		t.Fatalf("Trust() = %v, %v, want the recorded anchor", firstUse, err)
+	}
+
+	_, err = store.Trust("registry.example.com/app", "mallory")
+	var mismatch *x509nv2.SignerMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("Trust() error = %v, want SignerMismatchError", err)
+	}
+	if mismatch.Expected != "alice" || mismatch.Actual != "mallory" {
+		t.Errorf("Trust() error = %+v, want alice replaced by mallory", mismatch)
+	}
+
+	// the anchors are reloaded from the file
+	reloaded, err := x509nv2.NewTOFUStore(path)
+	if err != nil {
+		t.Fatalf("NewTOFUStore() error = %v", err)
+	}
+	if _, err := reloaded.Trust("registry.example.com/app", "mallory"); !errors.As(err, &mismatch) {
+		t.Errorf("Trust() after reload error = %v, want SignerMismatchError", err)
+	}
+	firstUse, err = reloaded.Trust("registry.example.com/other", "mallory")
+	if err != nil || !firstUse {
+		t.Errorf("Trust() of another image = %v, %v, want first use", firstUse, err)
+	}
+}
+
+func TestTOFUStoreTrustSaveError(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "missing")
+	store, err := x509nv2.NewTOFUStore(filepath.Join(dir, "tofu.json"))
+	if err != nil {
+		t.Fatalf("NewTOFUStore() error = %v", err)
+	}
+	if _, err := store.Trust("registry.example.com/app", "alice"); err == nil {
+		t.Fatal("Trust() error = nil, want the save error")
+	}
+
+	// the anchor failed to be saved, so it is not trusted
+	if err := os.Mkdir(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	firstUse, err := store.Trust("registry.example.com/app", "bob")
+	if err != nil || !firstUse {
+		t.Errorf("Trust() after a save error = %v, %v, want first use", firstUse, err)
+	}
+}
+
+func TestNewTOFUStoreMalformed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tofu.json")
+	if err := os.WriteFile(path, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := x509nv2.NewTOFUStore(path); err == nil {
+		t.Error("NewTOFUStore() error = nil, want an error for a malformed file")
+	}
+}
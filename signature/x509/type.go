@@ -2,3 +2,7 @@ package x509
 
 // Type indicates the signature type
 const Type = "x509"
+
+// AnnotationCosignCertificate is the annotation in which cosign embeds the PEM
+// encoded signing certificate chain
+const AnnotationCosignCertificate = "dev.cosignproject.cosign/certificate"
@@ -37,9 +37,34 @@ func ReadCertificateFile(path string) ([]*x509.Certificate, error) {
 	if err != nil {
 		return nil, err
 	}
+	return parseCertificatePEM(raw)
+}
+
+// ParseInlineCertificate parses the PEM encoded certificate chain embedded in
+// the cosign certificate annotation, and returns the leaf certificate and the
+// rest of the chain.
+func ParseInlineCertificate(annotations map[string]string) (*x509.Certificate, []*x509.Certificate, error) {
+	raw, ok := annotations[AnnotationCosignCertificate]
+	if !ok {
+		return nil, nil, errors.New("no inline certificate found")
+	}
+	certs, err := parseCertificatePEM([]byte(raw))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(certs) == 0 {
+		return nil, nil, errors.New("no PEM data found")
+	}
+	return certs[0], certs[1:], nil
+}
+
+func parseCertificatePEM(raw []byte) ([]*x509.Certificate, error) {
 	var certs []*x509.Certificate
 	block, rest := pem.Decode(raw)
 	for block != nil {
+		if block.Type != "CERTIFICATE" {
+			return nil, errors.New("unexpected PEM block type: " + block.Type)
+		}
 		cert, err := x509.ParseCertificate(block.Bytes)
 		if err != nil {
 			return nil, err
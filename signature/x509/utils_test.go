@@ -0,0 +1,116 @@
+package x509_test
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/docker/libtrust"
+	"github.com/notaryproject/notary/v2/signature"
+	x509nv2 "github.com/notaryproject/notary/v2/signature/x509"
+)
+
+func encodeCertificates(certs ...*x509.Certificate) string {
+	var b strings.Builder
+	for _, cert := range certs {
+		pem.Encode(&b, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	}
+	return b.String()
+}
+
+func TestParseInlineCertificate(t *testing.T) {
+	rootKey, root := newTestCertificate(t, "root", nil, nil)
+	_, leaf := newTestCertificate(t, "leaf", root, rootKey)
+	for _, tt := range []struct {
+		name        string
+		annotations map[string]string
+		wantChain   int
+		wantErr     bool
+	}{
+		{name: "leaf only", annotations: map[string]string{x509nv2.AnnotationCosignCertificate: encodeCertificates(leaf)}},
+		{name: "chain", annotations: map[string]string{x509nv2.AnnotationCosignCertificate: encodeCertificates(leaf, root)}, wantChain: 1},
+		{name: "missing annotation", annotations: map[string]string{}, wantErr: true},
+		{name: "no PEM block", annotations: map[string]string{x509nv2.AnnotationCosignCertificate: "certificate"}, wantErr: true},
+		{
+			name: "malformed certificate",
+			annotations: map[string]string{
+				x509nv2.AnnotationCosignCertificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("malformed")})),
+			},
+			wantErr: true,
+		},
+		{
+			name: "unexpected block type",
+			annotations: map[string]string{
+				x509nv2.AnnotationCosignCertificate: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: leaf.RawSubjectPublicKeyInfo})),
+			},
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, chain, err := x509nv2.ParseInlineCertificate(tt.annotations)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseInlineCertificate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !got.Equal(leaf) {
+				t.Errorf("ParseInlineCertificate() leaf = %v, want %v", got.Subject, leaf.Subject)
+			}
+			if len(chain) != tt.wantChain {
+				t.Errorf("ParseInlineCertificate() chain = %d certificates, want %d", len(chain), tt.wantChain)
+			}
+		})
+	}
+}
+
+func TestNewInlineVerifier(t *testing.T) {
+	rootKey, root := newTestCertificate(t, "root", nil, nil)
+	leafKey, leaf := newTestCertificate(t, "leaf", root, rootKey)
+	_, other := newTestCertificate(t, "other", nil, nil)
+
+	// the signature identifies the key by kid only
+	signingKey, err := libtrust.FromCryptoPrivateKey(leafKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := x509nv2.NewSigner(signingKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := signTestClaims(t, signer, "registry.example.com/test/app:v1")
+
+	annotations := map[string]string{
+		x509nv2.AnnotationCosignCertificate: encodeCertificates(leaf, root),
+	}
+	for _, tt := range []struct {
+		name        string
+		annotations map[string]string
+		root        *x509.Certificate
+		wantErr     bool
+	}{
+		{name: "valid chain", annotations: annotations, root: root},
+		{name: "untrusted chain", annotations: annotations, root: other, wantErr: true},
+		{name: "leaf only", annotations: map[string]string{x509nv2.AnnotationCosignCertificate: encodeCertificates(leaf)}, root: root},
+		{name: "self-signed", annotations: map[string]string{x509nv2.AnnotationCosignCertificate: encodeCertificates(other)}, root: root, wantErr: true},
+		{name: "malformed PEM block", annotations: map[string]string{x509nv2.AnnotationCosignCertificate: "-----BEGIN CERTIFICATE-----\n!!!\n-----END CERTIFICATE-----\n"}, root: root, wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			roots := x509.NewCertPool()
+			roots.AddCert(tt.root)
+			verifier, err := x509nv2.NewInlineVerifier(tt.annotations, roots)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewInlineVerifier() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			scheme := signature.NewScheme()
+			scheme.RegisterVerifier(verifier)
+			if _, err := scheme.Verify(string(sig)); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+		})
+	}
+}
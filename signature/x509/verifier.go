@@ -4,6 +4,7 @@ import (
 	"crypto"
 	"crypto/x509"
 	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/docker/go/canonical/json"
@@ -15,6 +16,10 @@ type verifier struct {
 	keys  map[string]libtrust.PublicKey
 	certs map[string]*x509.Certificate
 	roots *x509.CertPool
+
+	// inlineKeyID identifies the key of the inline certificate, which
+	// verifies signatures without a kid or x5c header
+	inlineKeyID string
 }
 
 // NewVerifier creates a verifier
@@ -53,6 +58,45 @@ func NewVerifier(certs []*x509.Certificate, roots *x509.CertPool) (signature.Ver
 	}, nil
 }
 
+// NewInlineVerifier creates a verifier from the certificate chain embedded in
+// the cosign certificate annotation, for signatures self-describing their
+// certificate instead of relying on certificates configured in advance.
+// The chain is verified against roots, or the system roots if roots is nil.
+func NewInlineVerifier(annotations map[string]string, roots *x509.CertPool) (signature.Verifier, error) {
+	leaf, chain, err := ParseInlineCertificate(annotations)
+	if err != nil {
+		return nil, err
+	}
+	if roots == nil {
+		if roots, err = x509.SystemCertPool(); err != nil {
+			return nil, err
+		}
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain {
+		intermediates.AddCert(cert)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Intermediates: intermediates,
+		Roots:         roots,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return nil, err
+	}
+
+	key, err := libtrust.FromCryptoPublicKey(crypto.PublicKey(leaf.PublicKey))
+	if err != nil {
+		return nil, err
+	}
+	keyID := key.KeyID()
+	return &verifier{
+		keys:        map[string]libtrust.PublicKey{keyID: key},
+		certs:       map[string]*x509.Certificate{keyID: leaf},
+		roots:       roots,
+		inlineKeyID: keyID,
+	}, nil
+}
+
 func (v *verifier) Type() string {
 	return Type
 }
@@ -65,6 +109,9 @@ func (v *verifier) Verify(header signature.Header, signed string, sig []byte) er
 	if err := json.Unmarshal(header.Raw, &params); err != nil {
 		return err
 	}
+	if params.ContentType != "" {
+		return fmt.Errorf("unexpected content type: %s", params.ContentType)
+	}
 
 	key, cert, err := v.getVerificationKeyPair(params)
 	if err != nil {
@@ -88,6 +135,8 @@ func (v *verifier) getVerificationKeyPair(params Parameters) (libtrust.PublicKey
 		return v.getVerificationKeyPairFromX5c(params.X5c)
 	case params.KeyID != "":
 		return v.getVerificationKeyPairFromKeyID(params.KeyID)
+	case v.inlineKeyID != "":
+		return v.getVerificationKeyPairFromKeyID(v.inlineKeyID)
 	default:
 		return nil, nil, errors.New("missing verification key")
 	}
@@ -96,11 +145,11 @@ func (v *verifier) getVerificationKeyPair(params Parameters) (libtrust.PublicKey
 func (v *verifier) getVerificationKeyPairFromKeyID(keyID string) (libtrust.PublicKey, *x509.Certificate, error) {
 	key, found := v.keys[keyID]
 	if !found {
-		return nil, nil, errors.New("key not found: " + keyID)
+		return nil, nil, fmt.Errorf("%w: key not found: %s", signature.ErrUnknownSigner, keyID)
 	}
 	cert, found := v.certs[keyID]
 	if !found {
-		return nil, nil, errors.New("cert not found: " + keyID)
+		return nil, nil, fmt.Errorf("%w: cert not found: %s", signature.ErrUnknownSigner, keyID)
 	}
 	return key, cert, nil
 }
@@ -2,12 +2,101 @@ package notary
 
 import (
 	"context"
+	"time"
 
 	oci "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 // SigningService provides signature signing and verification services.
 type SigningService interface {
-	Sign(ctx context.Context, desc oci.Descriptor, references ...string) ([]byte, error)
+	Sign(ctx context.Context, desc oci.Descriptor, opts ...SignOption) ([]byte, error)
 	Verify(ctx context.Context, desc oci.Descriptor, signature []byte) ([]string, error)
 }
+
+// SignOption configures the signing.
+type SignOption func(*SignOptions)
+
+// SignOptions contains the optional parameters for signing.
+type SignOptions struct {
+	// References are the references of the content to be signed.
+	References []string
+
+	// NotBefore is the time before which the signature is not valid.
+	NotBefore time.Time
+
+	// Expiry is the time after which the signature is not valid.
+	Expiry time.Time
+
+	// SignedAt overrides the signing time if not zero.
+	SignedAt time.Time
+
+	// Nonce overrides the random nonce if not empty.
+	Nonce string
+
+	// EmbedPublicKey embeds the public key of the signer in the signature.
+	EmbedPublicKey bool
+}
+
+// NewSignOptions applies the sign options.
+func NewSignOptions(opts ...SignOption) SignOptions {
+	var options SignOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// WithReferences adds the references of the content to the signature.
+func WithReferences(references ...string) SignOption {
+	return func(opts *SignOptions) {
+		opts.References = append(opts.References, references...)
+	}
+}
+
+// WithNotBefore makes the signature valid only from the given time,
+// for example for scheduled rollouts.
+func WithNotBefore(t time.Time) SignOption {
+	return func(opts *SignOptions) {
+		opts.NotBefore = t
+	}
+}
+
+// WithSignedAt sets the signing time to the given time instead of the current
+// time, so that reproducible builds can produce identical signature payloads,
+// e.g. by using the commit time. Combine with WithNonce to get byte-identical
+// payloads.
+//
+// The signing time is asserted by the signer rather than observed, so a fixed
+// time can backdate a signature. Verifiers must not rely on it to order
+// signatures or to reason about key compromise.
+func WithSignedAt(t time.Time) SignOption {
+	return func(opts *SignOptions) {
+		opts.SignedAt = t
+	}
+}
+
+// WithNonce sets the nonce instead of a random one.
+// A fixed nonce defeats the replay protection of nonce registries, and is only
+// meant for reproducible builds.
+func WithNonce(nonce string) SignOption {
+	return func(opts *SignOptions) {
+		opts.Nonce = nonce
+	}
+}
+
+// WithEmbedPublicKey embeds the public key of the signer in the signature
+// payload, so that its integrity can be verified without a trust store.
+// An embedded key proves nothing about the identity of the signer.
+func WithEmbedPublicKey() SignOption {
+	return func(opts *SignOptions) {
+		opts.EmbedPublicKey = true
+	}
+}
+
+// WithExpiry makes the signature valid only until the given time.
+// Together with WithNotBefore, it bounds the validity window of the signature.
+func WithExpiry(t time.Time) SignOption {
+	return func(opts *SignOptions) {
+		opts.Expiry = t
+	}
+}
@@ -3,6 +3,7 @@ package simple
 import (
 	"context"
 	"crypto/x509"
+	"encoding/base64"
 	"fmt"
 	"time"
 
@@ -15,11 +16,28 @@ import (
 
 type signingService struct {
 	*signature.Scheme
+	publicKey         string
+	verificationCerts []*x509.Certificate
+}
+
+// Option configures the signing service
+type Option func(*signature.Scheme)
+
+// WithNonceRegistry registers the nonce registry used to reject replayed
+// signatures, when verifying with a context returned by
+// notary.WithReplayCheck.
+func WithNonceRegistry(nonces *signature.NonceRegistry) Option {
+	return func(scheme *signature.Scheme) {
+		scheme.RegisterNonceRegistry(nonces)
+	}
 }
 
 // NewSigningService create a simple signing service.
-func NewSigningService(signingKey libtrust.PrivateKey, signingCerts, verificationCerts []*x509.Certificate, roots *x509.CertPool) (notary.SigningService, error) {
+func NewSigningService(signingKey libtrust.PrivateKey, signingCerts, verificationCerts []*x509.Certificate, roots *x509.CertPool, opts ...Option) (notary.SigningService, error) {
 	scheme := signature.NewScheme()
+	for _, opt := range opts {
+		opt(scheme)
+	}
 
 	if signingKey != nil {
 		signer, err := x509nv2.NewSigner(signingKey, signingCerts)
@@ -28,6 +46,14 @@ func NewSigningService(signingKey libtrust.PrivateKey, signingCerts, verificatio
 		}
 		scheme.RegisterSigner("", signer)
 	}
+	var publicKey string
+	if signingKey != nil {
+		der, err := x509.MarshalPKIXPublicKey(signingKey.PublicKey().CryptoPublicKey())
+		if err != nil {
+			return nil, err
+		}
+		publicKey = base64.StdEncoding.EncodeToString(der)
+	}
 
 	verifier, err := x509nv2.NewVerifier(verificationCerts, roots)
 	if err != nil {
@@ -36,17 +62,41 @@ func NewSigningService(signingKey libtrust.PrivateKey, signingCerts, verificatio
 	scheme.RegisterVerifier(verifier)
 
 	return &signingService{
-		Scheme: scheme,
+		Scheme:            scheme,
+		publicKey:         publicKey,
+		verificationCerts: verificationCerts,
 	}, nil
 }
 
-func (s *signingService) Sign(ctx context.Context, desc oci.Descriptor, references ...string) ([]byte, error) {
+func (s *signingService) Sign(ctx context.Context, desc oci.Descriptor, opts ...notary.SignOption) ([]byte, error) {
+	options := notary.NewSignOptions(opts...)
+	signedAt := options.SignedAt
+	if signedAt.IsZero() {
+		signedAt = time.Now()
+	}
+	nonce := options.Nonce
+	if nonce == "" {
+		var err error
+		if nonce, err = signature.NewNonce(); err != nil {
+			return nil, err
+		}
+	}
 	claims := signature.Claims{
 		Manifest: signature.Manifest{
 			Descriptor: convertDescriptor(desc),
-			References: references,
+			References: options.References,
 		},
-		IssuedAt: time.Now().Unix(),
+		IssuedAt: signedAt.Unix(),
+		Nonce:    nonce,
+	}
+	if !options.NotBefore.IsZero() {
+		claims.NotBefore = options.NotBefore.Unix()
+	}
+	if !options.Expiry.IsZero() {
+		claims.Expiration = options.Expiry.Unix()
+	}
+	if options.EmbedPublicKey {
+		claims.EmbeddedPublicKey = s.publicKey
 	}
 
 	sig, err := s.Scheme.Sign("", claims)
@@ -58,9 +108,27 @@ func (s *signingService) Sign(ctx context.Context, desc oci.Descriptor, referenc
 }
 
 func (s *signingService) Verify(ctx context.Context, desc oci.Descriptor, sig []byte) ([]string, error) {
-	claims, err := s.Scheme.Verify(string(sig))
+	format, err := signature.DetectSignatureFormat(sig)
+	if err != nil {
+		return nil, fmt.Errorf("verification failure: %w", err)
+	}
+	if format != signature.FormatJWS {
+		return nil, fmt.Errorf("verification failure: unsupported signature format: %s", format)
+	}
+
+	// the trust store carried by ctx takes the place of the roots given on
+	// construction
+	var opts []signature.VerifyOption
+	if roots, ok := notary.TrustStoreFromContext(ctx); ok {
+		verifier, err := x509nv2.NewVerifier(s.verificationCerts, roots)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, signature.WithVerifier(verifier))
+	}
+	claims, err := s.Scheme.Verify(string(sig), opts...)
 	if err != nil {
-		return nil, fmt.Errorf("verification failure: %v", err)
+		return nil, fmt.Errorf("verification failure: %w", err)
 	}
 
 	descriptor := convertDescriptor(desc)
@@ -71,6 +139,13 @@ func (s *signingService) Verify(ctx context.Context, desc oci.Descriptor, sig []
 		)
 	}
 
+	// the nonce is only recorded for signatures valid for the descriptor
+	if notary.ReplayCheckFromContext(ctx) {
+		if err := s.Scheme.CheckReplay(claims); err != nil {
+			return nil, fmt.Errorf("verification failure: %w", err)
+		}
+	}
+
 	return claims.Manifest.References, nil
 }
 
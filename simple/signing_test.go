@@ -0,0 +1,304 @@
+package simple_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/libtrust"
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/signature"
+	x509nv2 "github.com/notaryproject/notary/v2/signature/x509"
+	"github.com/notaryproject/notary/v2/simple"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// newTestKeyPair generates a P-256 key with a self-signed certificate
+func newTestKeyPair(t *testing.T) (libtrust.PrivateKey, *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "signer"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingKey, err := libtrust.FromCryptoPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signingKey, cert
+}
+
+func TestSigningServiceReplayCheck(t *testing.T) {
+	key, cert := newTestKeyPair(t)
+	certs := []*x509.Certificate{cert}
+	service, err := simple.NewSigningService(key, certs, certs, nil, simple.WithNonceRegistry(signature.NewNonceRegistry(time.Hour)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	desc := oci.Descriptor{
+		MediaType: oci.MediaTypeImageManifest,
+		Digest:    digest.FromString("manifest"),
+		Size:      8,
+	}
+	sig, err := service.Sign(ctx, desc)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	// verification without the replay check can be repeated
+	for i := 0; i < 2; i++ {
+		if _, err := service.Verify(ctx, desc, sig); err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+	}
+
+	// a failed verification does not record the nonce
+	replayCtx := notary.WithReplayCheck(ctx)
+	other := desc
+	other.Digest = digest.FromString("other")
+	if _, err := service.Verify(replayCtx, other, sig); err == nil {
+		t.Fatal("Verify() of another manifest succeeded")
+	}
+
+	if _, err := service.Verify(replayCtx, desc, sig); err != nil {
+		t.Fatalf("Verify() with replay check error = %v", err)
+	}
+	var replayed *signature.ReplayedNonceError
+	if _, err := service.Verify(replayCtx, desc, sig); !errors.As(err, &replayed) {
+		t.Fatalf("Verify() of a replayed signature error = %v, want ReplayedNonceError", err)
+	}
+}
+
+func TestSigningServiceReplayCheckWithoutNonceRegistry(t *testing.T) {
+	key, cert := newTestKeyPair(t)
+	certs := []*x509.Certificate{cert}
+	service, err := simple.NewSigningService(key, certs, certs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := notary.WithReplayCheck(context.Background())
+	desc := oci.Descriptor{
+		MediaType: oci.MediaTypeImageManifest,
+		Digest:    digest.FromString("manifest"),
+		Size:      8,
+	}
+	sig, err := service.Sign(ctx, desc)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if _, err := service.Verify(ctx, desc, sig); err == nil {
+		t.Fatal("Verify() with replay check but no nonce registry succeeded")
+	}
+}
+
+func TestSigningServiceTrustStoreFromContext(t *testing.T) {
+	key, cert := newTestKeyPair(t)
+	service, err := simple.NewSigningService(key, []*x509.Certificate{cert}, nil, x509.NewCertPool())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	desc := oci.Descriptor{
+		MediaType: oci.MediaTypeImageManifest,
+		Digest:    digest.FromString("manifest"),
+		Size:      8,
+	}
+	sig, err := service.Sign(ctx, desc)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if _, err := service.Verify(ctx, desc, sig); err == nil {
+		t.Fatal("Verify() with an empty trust store succeeded")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	if _, err := service.Verify(notary.WithTrustStore(ctx, roots), desc, sig); err != nil {
+		t.Fatalf("Verify() with the trust store from context error = %v", err)
+	}
+}
+
+func TestSigningServiceValidityWindow(t *testing.T) {
+	key, cert := newTestKeyPair(t)
+	certs := []*x509.Certificate{cert}
+	service, err := simple.NewSigningService(key, certs, certs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	desc := oci.Descriptor{
+		MediaType: oci.MediaTypeImageManifest,
+		Digest:    digest.FromString("manifest"),
+		Size:      8,
+	}
+	now := time.Now()
+	for _, tt := range []struct {
+		name        string
+		notBefore   time.Time
+		expiry      time.Time
+		notYetValid bool
+		expired     bool
+	}{
+		{name: "before the window", notBefore: now.Add(time.Hour), expiry: now.Add(2 * time.Hour), notYetValid: true},
+		{name: "at the start of the window", notBefore: now, expiry: now.Add(time.Hour)},
+		{name: "within the window", notBefore: now.Add(-time.Hour), expiry: now.Add(time.Hour)},
+		{name: "after the window", notBefore: now.Add(-2 * time.Hour), expiry: now.Add(-time.Hour), expired: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, err := service.Sign(ctx, desc, notary.WithNotBefore(tt.notBefore), notary.WithExpiry(tt.expiry))
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			_, err = service.Verify(ctx, desc, sig)
+			if (err != nil) != (tt.notYetValid || tt.expired) {
+				t.Fatalf("Verify() error = %v, want not yet valid %v, expired %v", err, tt.notYetValid, tt.expired)
+			}
+
+			var notYetValid *signature.SignatureNotYetValidError
+			if got := errors.As(err, &notYetValid); got != tt.notYetValid {
+				t.Fatalf("Verify() error = %v, want SignatureNotYetValidError %v", err, tt.notYetValid)
+			}
+			if tt.notYetValid {
+				if !notYetValid.NotBefore.Equal(tt.notBefore.Truncate(time.Second)) {
+					t.Errorf("NotBefore = %v, want %v", notYetValid.NotBefore, tt.notBefore)
+				}
+				if !errors.Is(err, notary.ErrNotYetValid) {
+					t.Errorf("Verify() error = %v, want ErrNotYetValid", err)
+				}
+			}
+
+			var expired *signature.SignatureExpiredError
+			if got := errors.As(err, &expired); got != tt.expired {
+				t.Fatalf("Verify() error = %v, want SignatureExpiredError %v", err, tt.expired)
+			}
+			if tt.expired {
+				if !expired.Expiry.Equal(tt.expiry.Truncate(time.Second)) {
+					t.Errorf("Expiry = %v, want %v", expired.Expiry, tt.expiry)
+				}
+				if !errors.Is(err, notary.ErrExpired) {
+					t.Errorf("Verify() error = %v, want ErrExpired", err)
+				}
+			}
+		})
+	}
+}
+
+func TestSigningServiceSignedAt(t *testing.T) {
+	key, cert := newTestKeyPair(t)
+	certs := []*x509.Certificate{cert}
+	service, err := simple.NewSigningService(key, certs, certs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	desc := oci.Descriptor{
+		MediaType: oci.MediaTypeImageManifest,
+		Digest:    digest.FromString("manifest"),
+		Size:      8,
+	}
+	signedAt := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	payload := func(opts ...notary.SignOption) string {
+		t.Helper()
+		sig, err := service.Sign(ctx, desc, opts...)
+		if err != nil {
+			t.Fatalf("Sign() error = %v", err)
+		}
+		claims, err := signature.UnverifiedClaims(sig)
+		if err != nil {
+			t.Fatalf("UnverifiedClaims() error = %v", err)
+		}
+		if claims.IssuedAt != signedAt.Unix() {
+			t.Errorf("IssuedAt = %d, want %d", claims.IssuedAt, signedAt.Unix())
+		}
+		return strings.Split(string(sig), ".")[1]
+	}
+
+	if payload(notary.WithSignedAt(signedAt)) == payload(notary.WithSignedAt(signedAt)) {
+		t.Error("payloads with random nonces are identical")
+	}
+	opts := []notary.SignOption{notary.WithSignedAt(signedAt), notary.WithNonce("nonce")}
+	if first, second := payload(opts...), payload(opts...); first != second {
+		t.Errorf("payloads differ: %s != %s", first, second)
+	}
+}
+
+func TestSigningServiceEmbedPublicKey(t *testing.T) {
+	key, cert := newTestKeyPair(t)
+	certs := []*x509.Certificate{cert}
+	service, err := simple.NewSigningService(key, certs, certs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	desc := oci.Descriptor{
+		MediaType: oci.MediaTypeImageManifest,
+		Digest:    digest.FromString("manifest"),
+		Size:      8,
+	}
+
+	sig, err := service.Sign(ctx, desc, notary.WithEmbedPublicKey())
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	var warning *x509nv2.EmbeddedKeyVerificationWarning
+	if err := x509nv2.VerifyEmbedded(sig); !errors.As(err, &warning) {
+		t.Fatalf("VerifyEmbedded() error = %v, want an EmbeddedKeyVerificationWarning", err)
+	}
+	if got := warning.Claims.Manifest.Descriptor.Digest; got != desc.Digest.String() {
+		t.Errorf("VerifyEmbedded() digest = %s, want %s", got, desc.Digest)
+	}
+
+	// the key is only embedded on request
+	plain, err := service.Sign(ctx, desc)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if claims, err := signature.UnverifiedClaims(plain); err != nil || claims.EmbeddedPublicKey != "" {
+		t.Errorf("UnverifiedClaims() = %+v, %v, want no embedded public key", claims, err)
+	}
+	if err := x509nv2.VerifyEmbedded(plain); err == nil || errors.As(err, &warning) {
+		t.Errorf("VerifyEmbedded() error = %v, want missing embedded public key", err)
+	}
+
+	// a signature by another key fails against the embedded key
+	otherKey, otherCert := newTestKeyPair(t)
+	otherCerts := []*x509.Certificate{otherCert}
+	other, err := simple.NewSigningService(otherKey, otherCerts, otherCerts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSig, err := other.Sign(ctx, desc, notary.WithEmbedPublicKey())
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	parts := strings.Split(string(sig), ".")
+	forged := strings.Join(append(parts[:2], strings.Split(string(otherSig), ".")[2]), ".")
+	if err := x509nv2.VerifyEmbedded([]byte(forged)); err == nil || errors.As(err, &warning) {
+		t.Errorf("VerifyEmbedded() error = %v, want a verification failure", err)
+	}
+}
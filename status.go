@@ -0,0 +1,139 @@
+package notary
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/notaryproject/notary/v2/signature"
+	x509nv2 "github.com/notaryproject/notary/v2/signature/x509"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// SigningStatus describes the signing status of a manifest
+type SigningStatus struct {
+	Signed         bool
+	SignatureCount int
+
+	// Signers and LastSignedAt are only set with WithSignerDetails
+	Signers      []string
+	LastSignedAt time.Time
+}
+
+// StatusOption is an option for BulkStatus
+type StatusOption func(*statusOptions)
+
+type statusOptions struct {
+	concurrency int
+	details     bool
+}
+
+// WithStatusConcurrency limits the number of subjects looked up concurrently
+// by BulkStatus. It defaults to the number of subjects, up to GOMAXPROCS.
+func WithStatusConcurrency(n int) StatusOption {
+	return func(opts *statusOptions) {
+		opts.concurrency = n
+	}
+}
+
+// WithSignerDetails fills the signers and the last signing time of the
+// statuses, which requires downloading every signature blob.
+func WithSignerDetails() StatusOption {
+	return func(opts *statusOptions) {
+		opts.details = true
+	}
+}
+
+// BulkStatus looks up the signing status of the subjects in parallel.
+// Whether a subject is signed is decided from the referrers alone, without
+// downloading the signatures. With WithSignerDetails, the signers and signing
+// times are read from the signatures without verifying them, and therefore
+// are for information only.
+// If the lookup fails for some subjects, the statuses of the others are
+// returned with a PartialResultError.
+func BulkStatus(ctx context.Context, repo SignatureRepository, subjects []oci.Descriptor, opts ...StatusOption) (map[digest.Digest]SigningStatus, error) {
+	var options statusOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	concurrency := options.concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+	if concurrency > len(subjects) {
+		concurrency = len(subjects)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		statuses = make(map[digest.Digest]SigningStatus, len(subjects))
+		errs     = make(map[digest.Digest]error)
+		digests  = make(chan digest.Digest)
+	)
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for subjectDigest := range digests {
+				status, err := signingStatus(ctx, repo, subjectDigest, options.details)
+				mu.Lock()
+				if err != nil {
+					errs[subjectDigest] = err
+				} else {
+					statuses[subjectDigest] = status
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, subject := range subjects {
+		digests <- subject.Digest
+	}
+	close(digests)
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return statuses, &PartialResultError{
+			NotaryError: NotaryError{
+				Code: ErrCodePartialResult,
+				Op:   "bulk status",
+			},
+			Errors: errs,
+		}
+	}
+	return statuses, nil
+}
+
+func signingStatus(ctx context.Context, repo SignatureRepository, subjectDigest digest.Digest, details bool) (SigningStatus, error) {
+	signatures, err := repo.LookupSet(ctx, subjectDigest)
+	if err != nil {
+		return SigningStatus{}, err
+	}
+	status := SigningStatus{
+		Signed:         signatures.Len() > 0,
+		SignatureCount: signatures.Len(),
+	}
+	if !details {
+		return status, nil
+	}
+	seen := make(map[string]bool)
+	for _, ref := range signatures.ToSlice() {
+		sig, err := repo.Get(ctx, ref.SignatureBlobDescriptor.Digest)
+		if err != nil {
+			return SigningStatus{}, err
+		}
+		if signer, err := x509nv2.SignerCommonName(sig); err == nil && !seen[signer] {
+			seen[signer] = true
+			status.Signers = append(status.Signers, signer)
+		}
+		if claims, err := signature.UnverifiedClaims(sig); err == nil && claims.IssuedAt != 0 {
+			if signedAt := time.Unix(claims.IssuedAt, 0); signedAt.After(status.LastSignedAt) {
+				status.LastSignedAt = signedAt
+			}
+		}
+	}
+	return status, nil
+}
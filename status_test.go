@@ -0,0 +1,144 @@
+package notary_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/registry"
+	"github.com/notaryproject/notary/v2/registry/registrytest"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestBulkStatus(t *testing.T) {
+	server := registrytest.NewServer()
+	defer server.Close()
+	repo := registry.NewRepository(http.DefaultTransport, server.Host(), "test/app", true)
+
+	signed := oci.Descriptor{
+		MediaType: oci.MediaTypeImageManifest,
+		Digest:    digest.FromString("signed"),
+		Size:      6,
+	}
+	unsigned := oci.Descriptor{
+		MediaType: oci.MediaTypeImageManifest,
+		Digest:    digest.FromString("unsigned"),
+		Size:      8,
+	}
+	before := time.Now().Add(-time.Second)
+	signAndLink(t, repo, newTestSigningService(t, "alice"), signed)
+	signAndLink(t, repo, newTestSigningService(t, "bob"), signed)
+
+	repo.ResetStats()
+	statuses, err := notary.BulkStatus(context.Background(), repo, []oci.Descriptor{signed, unsigned})
+	if err != nil {
+		t.Fatalf("BulkStatus() error = %v", err)
+	}
+	if status := statuses[signed.Digest]; !status.Signed || status.SignatureCount != 2 || len(status.Signers) != 0 {
+		t.Errorf("BulkStatus() signed status = %+v, want 2 signatures without details", status)
+	}
+	if got := repo.Stats().GetTotal; got != 0 {
+		t.Errorf("BulkStatus() fetched %d signatures, want none", got)
+	}
+
+	statuses, err = notary.BulkStatus(context.Background(), repo, []oci.Descriptor{signed, unsigned}, notary.WithSignerDetails())
+	if err != nil {
+		t.Fatalf("BulkStatus() error = %v", err)
+	}
+	status := statuses[signed.Digest]
+	if !status.Signed || status.SignatureCount != 2 {
+		t.Errorf("BulkStatus() signed status = %+v, want 2 signatures", status)
+	}
+	signers := map[string]bool{}
+	for _, signer := range status.Signers {
+		signers[signer] = true
+	}
+	if want := map[string]bool{"alice": true, "bob": true}; !reflect.DeepEqual(signers, want) {
+		t.Errorf("BulkStatus() signers = %v, want alice and bob", status.Signers)
+	}
+	if status.LastSignedAt.Before(before) || status.LastSignedAt.After(time.Now()) {
+		t.Errorf("BulkStatus() last signed at = %v, want the signing time", status.LastSignedAt)
+	}
+
+	if status := statuses[unsigned.Digest]; status.Signed || status.SignatureCount != 0 || len(status.Signers) != 0 {
+		t.Errorf("BulkStatus() unsigned status = %+v, want unsigned", status)
+	}
+}
+
+func TestBulkStatusPartialResult(t *testing.T) {
+	server := registrytest.NewServer()
+	repo := registry.NewRepository(http.DefaultTransport, server.Host(), "test/app", true)
+	server.Close()
+
+	subject := oci.Descriptor{
+		MediaType: oci.MediaTypeImageManifest,
+		Digest:    digest.FromString("subject"),
+		Size:      7,
+	}
+	_, err := notary.BulkStatus(context.Background(), repo, []oci.Descriptor{subject})
+	var partial *notary.PartialResultError
+	if !errors.As(err, &partial) || partial.Errors[subject.Digest] == nil {
+		t.Fatalf("BulkStatus() error = %v, want PartialResultError of the subject", err)
+	}
+	if !errors.Is(err, notary.ErrPartialResult) {
+		t.Errorf("BulkStatus() error = %v, want %v", err, notary.ErrPartialResult)
+	}
+}
+
+// concurrencyRepository records the maximum number of concurrent lookups
+type concurrencyRepository struct {
+	notary.SignatureRepository
+
+	mu       sync.Mutex
+	inFlight int
+	max      int
+}
+
+func (r *concurrencyRepository) LookupSet(ctx context.Context, manifestDigest digest.Digest, opts ...notary.LookupOption) (*notary.SignatureSet, error) {
+	r.mu.Lock()
+	r.inFlight++
+	if r.inFlight > r.max {
+		r.max = r.inFlight
+	}
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.inFlight--
+		r.mu.Unlock()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	return r.SignatureRepository.LookupSet(ctx, manifestDigest, opts...)
+}
+
+func TestBulkStatusConcurrency(t *testing.T) {
+	server := registrytest.NewServer()
+	defer server.Close()
+	repo := &concurrencyRepository{
+		SignatureRepository: registry.NewRepository(http.DefaultTransport, server.Host(), "test/app", true),
+	}
+
+	var subjects []oci.Descriptor
+	for i := 0; i < 8; i++ {
+		subjects = append(subjects, oci.Descriptor{
+			MediaType: oci.MediaTypeImageManifest,
+			Digest:    digest.FromString(fmt.Sprint("subject", i)),
+		})
+	}
+	statuses, err := notary.BulkStatus(context.Background(), repo, subjects, notary.WithStatusConcurrency(2))
+	if err != nil {
+		t.Fatalf("BulkStatus() error = %v", err)
+	}
+	if len(statuses) != len(subjects) {
+		t.Errorf("BulkStatus() returned %d statuses, want %d", len(statuses), len(subjects))
+	}
+	if repo.max > 2 {
+		t.Errorf("BulkStatus() looked up %d subjects concurrently, want at most 2", repo.max)
+	}
+}
@@ -0,0 +1,29 @@
+package notary
+
+import (
+	"context"
+
+	artifactspec "github.com/opencontainers/artifacts/specs-go/v2"
+	"github.com/opencontainers/go-digest"
+)
+
+// ArtifactStore provides a storage for manifests, blobs and the artifacts
+// referring to manifests, backed by a registry or any other storage.
+type ArtifactStore interface {
+	// GetManifest downloads the manifest by the specified digest, and returns
+	// it with its media type
+	GetManifest(ctx context.Context, d digest.Digest) ([]byte, string, error)
+
+	// PutManifest uploads the manifest of the specified media type
+	PutManifest(ctx context.Context, manifest []byte, mediaType string) (digest.Digest, error)
+
+	// GetBlob downloads the blob by the specified digest
+	GetBlob(ctx context.Context, d digest.Digest) ([]byte, error)
+
+	// PutBlob uploads the blob
+	PutBlob(ctx context.Context, data []byte) (digest.Digest, error)
+
+	// ListReferrers finds all artifact manifests of the specified artifact
+	// type referring to the subject manifest
+	ListReferrers(ctx context.Context, subject digest.Digest, artifactType string) ([]artifactspec.Artifact, error)
+}
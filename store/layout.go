@@ -0,0 +1,191 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	artifactspec "github.com/opencontainers/artifacts/specs-go/v2"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// OCILayoutArtifactStore is an artifact store on disk in the OCI image layout.
+// The manifests are listed in the index.json of the layout.
+type OCILayoutArtifactStore struct {
+	root string
+
+	mu    sync.RWMutex
+	index oci.Index
+}
+
+// NewOCILayoutArtifactStore opens the OCI image layout at root, or creates it
+// if it does not exist.
+func NewOCILayoutArtifactStore(root string) (*OCILayoutArtifactStore, error) {
+	s := &OCILayoutArtifactStore{
+		root: root,
+		index: oci.Index{
+			Versioned: specs.Versioned{
+				SchemaVersion: 2,
+			},
+		},
+	}
+	if err := os.MkdirAll(filepath.Join(root, "blobs"), 0755); err != nil {
+		return nil, err
+	}
+
+	layoutPath := filepath.Join(root, oci.ImageLayoutFile)
+	if _, err := os.Stat(layoutPath); os.IsNotExist(err) {
+		layout, err := json.Marshal(oci.ImageLayout{
+			Version: oci.ImageLayoutVersion,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeFile(layoutPath, layout); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	raw, err := ioutil.ReadFile(s.indexPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &s.index); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// GetManifest returns the manifest and its media type
+func (s *OCILayoutArtifactStore) GetManifest(ctx context.Context, d digest.Digest) ([]byte, string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, desc := range s.index.Manifests {
+		if desc.Digest == d {
+			manifest, err := s.readBlob(d)
+			if err != nil {
+				return nil, "", err
+			}
+			return manifest, desc.MediaType, nil
+		}
+	}
+	return nil, "", notFound("get manifest", d)
+}
+
+// PutManifest stores the manifest of the media type, and lists it in the index
+func (s *OCILayoutArtifactStore) PutManifest(ctx context.Context, manifest []byte, mediaType string) (digest.Digest, error) {
+	d, err := s.writeBlob(manifest)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, desc := range s.index.Manifests {
+		if desc.Digest == d {
+			return d, nil
+		}
+	}
+	s.index.Manifests = append(s.index.Manifests, oci.Descriptor{
+		MediaType: mediaType,
+		Digest:    d,
+		Size:      int64(len(manifest)),
+	})
+	index, err := json.Marshal(s.index)
+	if err != nil {
+		return "", err
+	}
+	return d, writeFile(s.indexPath(), index)
+}
+
+// GetBlob returns the blob
+func (s *OCILayoutArtifactStore) GetBlob(ctx context.Context, d digest.Digest) ([]byte, error) {
+	return s.readBlob(d)
+}
+
+// PutBlob stores the blob
+func (s *OCILayoutArtifactStore) PutBlob(ctx context.Context, data []byte) (digest.Digest, error) {
+	return s.writeBlob(data)
+}
+
+// ListReferrers finds the artifact manifests of the artifact type referring to
+// the subject
+func (s *OCILayoutArtifactStore) ListReferrers(ctx context.Context, subject digest.Digest, artifactType string) ([]artifactspec.Artifact, error) {
+	s.mu.RLock()
+	manifests := append([]oci.Descriptor(nil), s.index.Manifests...)
+	s.mu.RUnlock()
+	return referrers(manifests, s.readBlob, subject, artifactType)
+}
+
+func (s *OCILayoutArtifactStore) indexPath() string {
+	return filepath.Join(s.root, "index.json")
+}
+
+func (s *OCILayoutArtifactStore) blobPath(d digest.Digest) (string, error) {
+	if err := d.Validate(); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, "blobs", d.Algorithm().String(), d.Encoded()), nil
+}
+
+// readBlob reads the blob, and verifies its digest against corruption
+func (s *OCILayoutArtifactStore) readBlob(d digest.Digest) ([]byte, error) {
+	path, err := s.blobPath(d)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, notFound("get blob", d)
+		}
+		return nil, err
+	}
+	if actual := d.Algorithm().FromBytes(blob); actual != d {
+		return nil, fmt.Errorf("mismatch digest: expect %v: got %v", d, actual)
+	}
+	return blob, nil
+}
+
+func (s *OCILayoutArtifactStore) writeBlob(data []byte) (digest.Digest, error) {
+	d := digest.FromBytes(data)
+	path, err := s.blobPath(d)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return d, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	return d, writeFile(path, data)
+}
+
+// writeFile writes the file atomically through a temporary file
+func writeFile(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
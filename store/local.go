@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"sync"
+
+	artifactspec "github.com/opencontainers/artifacts/specs-go/v2"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// LocalArtifactStore is an artifact store in memory
+type LocalArtifactStore struct {
+	mu        sync.RWMutex
+	manifests map[digest.Digest]oci.Descriptor
+	blobs     map[digest.Digest][]byte
+}
+
+// NewLocalArtifactStore creates an empty artifact store in memory
+func NewLocalArtifactStore() *LocalArtifactStore {
+	return &LocalArtifactStore{
+		manifests: make(map[digest.Digest]oci.Descriptor),
+		blobs:     make(map[digest.Digest][]byte),
+	}
+}
+
+// GetManifest returns the manifest and its media type
+func (s *LocalArtifactStore) GetManifest(ctx context.Context, d digest.Digest) ([]byte, string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	desc, found := s.manifests[d]
+	if !found {
+		return nil, "", notFound("get manifest", d)
+	}
+	return copyBytes(s.blobs[d]), desc.MediaType, nil
+}
+
+// PutManifest stores the manifest of the media type
+func (s *LocalArtifactStore) PutManifest(ctx context.Context, manifest []byte, mediaType string) (digest.Digest, error) {
+	d := digest.FromBytes(manifest)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs[d] = copyBytes(manifest)
+	s.manifests[d] = oci.Descriptor{
+		MediaType: mediaType,
+		Digest:    d,
+		Size:      int64(len(manifest)),
+	}
+	return d, nil
+}
+
+// GetBlob returns the blob
+func (s *LocalArtifactStore) GetBlob(ctx context.Context, d digest.Digest) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	blob, found := s.blobs[d]
+	if !found {
+		return nil, notFound("get blob", d)
+	}
+	return copyBytes(blob), nil
+}
+
+// PutBlob stores the blob
+func (s *LocalArtifactStore) PutBlob(ctx context.Context, data []byte) (digest.Digest, error) {
+	d := digest.FromBytes(data)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs[d] = copyBytes(data)
+	return d, nil
+}
+
+// ListReferrers finds the artifact manifests of the artifact type referring to
+// the subject
+func (s *LocalArtifactStore) ListReferrers(ctx context.Context, subject digest.Digest, artifactType string) ([]artifactspec.Artifact, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	manifests := make([]oci.Descriptor, 0, len(s.manifests))
+	for _, desc := range s.manifests {
+		manifests = append(manifests, desc)
+	}
+	return referrers(manifests, func(d digest.Digest) ([]byte, error) {
+		return s.blobs[d], nil
+	}, subject, artifactType)
+}
+
+func copyBytes(b []byte) []byte {
+	return append([]byte(nil), b...)
+}
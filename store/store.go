@@ -0,0 +1,50 @@
+package store
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/registry"
+	artifactspec "github.com/opencontainers/artifacts/specs-go/v2"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// notFound returns the error for content not found in the store
+func notFound(op string, d digest.Digest) error {
+	return &notary.NotaryError{
+		Code:  notary.ErrCodeNotFound,
+		Op:    op,
+		Cause: errors.New(d.String()),
+	}
+}
+
+// referrers filters the artifact manifests, or the image manifests carrying
+// artifacts, of the artifact type referring to the subject among the
+// manifests, read by the read function.
+func referrers(manifests []oci.Descriptor, read func(digest.Digest) ([]byte, error), subject digest.Digest, artifactType string) ([]artifactspec.Artifact, error) {
+	sort.Slice(manifests, func(i, j int) bool {
+		return manifests[i].Digest < manifests[j].Digest
+	})
+	var artifacts []artifactspec.Artifact
+	for _, desc := range manifests {
+		switch desc.MediaType {
+		case artifactspec.MediaTypeArtifactManifest, registry.MediaTypeOCIArtifactManifest, oci.MediaTypeImageManifest:
+		default:
+			continue
+		}
+		manifest, err := read(desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		artifact, err := registry.ParseArtifactManifest(manifest, desc.MediaType)
+		if err != nil {
+			return nil, err
+		}
+		if artifact.ArtifactType == artifactType && artifact.SubjectManifest.Digest == subject {
+			artifacts = append(artifacts, artifact)
+		}
+	}
+	return artifacts, nil
+}
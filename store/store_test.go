@@ -0,0 +1,174 @@
+package store_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/registry"
+	"github.com/notaryproject/notary/v2/registry/registrytest"
+	"github.com/notaryproject/notary/v2/store"
+	artifactspecs "github.com/opencontainers/artifacts/specs-go"
+	artifactspec "github.com/opencontainers/artifacts/specs-go/v2"
+	"github.com/opencontainers/go-digest"
+)
+
+// newTestArtifact encodes an artifact manifest of the artifact type referring
+// to the subject
+func newTestArtifact(t *testing.T, artifactType string, subject digest.Digest, blob digest.Digest) []byte {
+	t.Helper()
+	manifest, err := json.Marshal(artifactspec.Artifact{
+		Versioned:    artifactspecs.Versioned{SchemaVersion: 2},
+		MediaType:    artifactspec.MediaTypeArtifactManifest,
+		ArtifactType: artifactType,
+		Blobs: []artifactspec.Descriptor{{
+			MediaType: "application/octet-stream",
+			Digest:    blob,
+			Size:      9,
+		}},
+		SubjectManifest: artifactspec.Descriptor{
+			MediaType: "application/vnd.oci.image.manifest.v1+json",
+			Digest:    subject,
+			Size:      7,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return manifest
+}
+
+func TestArtifactStores(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		newStore func(t *testing.T) notary.ArtifactStore
+	}{
+		{
+			name: "local",
+			newStore: func(t *testing.T) notary.ArtifactStore {
+				return store.NewLocalArtifactStore()
+			},
+		},
+		{
+			name: "OCI layout",
+			newStore: func(t *testing.T) notary.ArtifactStore {
+				s, err := store.NewOCILayoutArtifactStore(t.TempDir())
+				if err != nil {
+					t.Fatalf("NewOCILayoutArtifactStore() error = %v", err)
+				}
+				return s
+			},
+		},
+		{
+			name: "registry",
+			newStore: func(t *testing.T) notary.ArtifactStore {
+				server := registrytest.NewServer()
+				t.Cleanup(server.Close)
+				return registry.NewRepository(http.DefaultTransport, server.Host(), "test/app", true)
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := tt.newStore(t)
+
+			blobDigest, err := s.PutBlob(ctx, []byte("signature"))
+			if err != nil {
+				t.Fatalf("PutBlob() error = %v", err)
+			}
+			if blob, err := s.GetBlob(ctx, blobDigest); err != nil || string(blob) != "signature" {
+				t.Errorf("GetBlob() = %q, %v, want the stored blob", blob, err)
+			}
+
+			subject := digest.FromString("subject")
+			artifact := newTestArtifact(t, "application/vnd.cncf.notary.v2", subject, blobDigest)
+			artifactDigest, err := s.PutManifest(ctx, artifact, artifactspec.MediaTypeArtifactManifest)
+			if err != nil {
+				t.Fatalf("PutManifest() error = %v", err)
+			}
+			other := newTestArtifact(t, "application/vnd.example.sbom", subject, blobDigest)
+			if _, err := s.PutManifest(ctx, other, artifactspec.MediaTypeArtifactManifest); err != nil {
+				t.Fatalf("PutManifest() error = %v", err)
+			}
+			manifest, mediaType, err := s.GetManifest(ctx, artifactDigest)
+			if err != nil || string(manifest) != string(artifact) || mediaType != artifactspec.MediaTypeArtifactManifest {
+				t.Errorf("GetManifest() = %q, %q, %v, want the stored manifest", manifest, mediaType, err)
+			}
+
+			referrers, err := s.ListReferrers(ctx, subject, "application/vnd.cncf.notary.v2")
+			if err != nil {
+				t.Fatalf("ListReferrers() error = %v", err)
+			}
+			if len(referrers) != 1 || referrers[0].Blobs[0].Digest != blobDigest {
+				t.Errorf("ListReferrers() = %+v, want the notary artifact only", referrers)
+			}
+			if referrers, err := s.ListReferrers(ctx, digest.FromString("other"), "application/vnd.cncf.notary.v2"); err != nil || len(referrers) != 0 {
+				t.Errorf("ListReferrers() of another subject = %+v, %v, want none", referrers, err)
+			}
+
+			missing := digest.FromString("missing")
+			if _, err := s.GetBlob(ctx, missing); err == nil {
+				t.Error("GetBlob() of a missing blob error = nil, want an error")
+			}
+			if _, _, err := s.GetManifest(ctx, missing); err == nil {
+				t.Error("GetManifest() of a missing manifest error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestLocalArtifactStoreNotFound(t *testing.T) {
+	s := store.NewLocalArtifactStore()
+	if _, err := s.GetBlob(context.Background(), digest.FromString("missing")); !errors.Is(err, notary.ErrNotFound) {
+		t.Errorf("GetBlob() error = %v, want %v", err, notary.ErrNotFound)
+	}
+}
+
+func TestOCILayoutArtifactStoreReopen(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	s, err := store.NewOCILayoutArtifactStore(root)
+	if err != nil {
+		t.Fatalf("NewOCILayoutArtifactStore() error = %v", err)
+	}
+	manifestDigest, err := s.PutManifest(ctx, []byte(`{"schemaVersion":2}`), "application/vnd.oci.image.manifest.v1+json")
+	if err != nil {
+		t.Fatalf("PutManifest() error = %v", err)
+	}
+
+	reopened, err := store.NewOCILayoutArtifactStore(root)
+	if err != nil {
+		t.Fatalf("NewOCILayoutArtifactStore() error = %v", err)
+	}
+	if _, _, err := reopened.GetManifest(ctx, manifestDigest); err != nil {
+		t.Errorf("GetManifest() after reopen error = %v", err)
+	}
+	if _, _, err := reopened.GetManifest(ctx, digest.FromString("missing")); !errors.Is(err, notary.ErrNotFound) {
+		t.Errorf("GetManifest() error = %v, want %v", err, notary.ErrNotFound)
+	}
+}
+
+func TestOCILayoutArtifactStoreCorruptBlob(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	s, err := store.NewOCILayoutArtifactStore(root)
+	if err != nil {
+		t.Fatalf("NewOCILayoutArtifactStore() error = %v", err)
+	}
+	d, err := s.PutBlob(ctx, []byte("signature"))
+	if err != nil {
+		t.Fatalf("PutBlob() error = %v", err)
+	}
+	path := filepath.Join(root, "blobs", d.Algorithm().String(), d.Encoded())
+	if err := ioutil.WriteFile(path, []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetBlob(ctx, d); err == nil {
+		t.Error("GetBlob() of a corrupt blob error = nil, want a digest mismatch")
+	}
+}
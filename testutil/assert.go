@@ -0,0 +1,91 @@
+package testutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/notaryproject/notary/v2"
+	x509nv2 "github.com/notaryproject/notary/v2/signature/x509"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// AssertOption configures the assertions.
+type AssertOption func(*assertOptions)
+
+type assertOptions struct {
+	signOptions []notary.SignOption
+	signerCN    string
+}
+
+// WithSignOptions signs the subject with the sign options, e.g. the references.
+func WithSignOptions(opts ...notary.SignOption) AssertOption {
+	return func(o *assertOptions) {
+		o.signOptions = append(o.signOptions, opts...)
+	}
+}
+
+// WithSignerCN expects the signing certificate to have the common name.
+func WithSignerCN(cn string) AssertOption {
+	return func(o *assertOptions) {
+		o.signerCN = cn
+	}
+}
+
+// AssertSignatureValid signs the subject, uploads and links the signature,
+// looks it up again, and verifies it, failing the test on any error.
+func AssertSignatureValid(t testing.TB, repo notary.SignatureRepository, subject oci.Descriptor, service notary.SigningService, opts ...AssertOption) {
+	t.Helper()
+	var options assertOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	ctx := context.Background()
+
+	sig, err := service.Sign(ctx, subject, options.signOptions...)
+	if err != nil {
+		t.Fatalf("failed to sign %v: %v", subject.Digest, err)
+	}
+	signature, err := repo.Put(ctx, sig)
+	if err != nil {
+		t.Fatalf("failed to put signature: %v", err)
+	}
+	if _, err := repo.Link(ctx, subject, signature); err != nil {
+		t.Fatalf("failed to link signature: %v", err)
+	}
+
+	set, err := repo.LookupSet(ctx, subject.Digest)
+	if err != nil {
+		t.Fatalf("failed to lookup signatures of %v: %v", subject.Digest, err)
+	}
+	if !set.Contains(signature.Digest) {
+		t.Fatalf("signature %v not found for %v", signature.Digest, subject.Digest)
+	}
+	fetched, err := repo.Get(ctx, signature.Digest)
+	if err != nil {
+		t.Fatalf("failed to get signature %v: %v", signature.Digest, err)
+	}
+	if _, err := service.Verify(ctx, subject, fetched); err != nil {
+		t.Fatalf("failed to verify signature %v: %v", signature.Digest, err)
+	}
+	if options.signerCN != "" {
+		cn, err := x509nv2.SignerCommonName(fetched)
+		if err != nil {
+			t.Fatalf("failed to read signer of %v: %v", signature.Digest, err)
+		}
+		if cn != options.signerCN {
+			t.Fatalf("signer mismatch: expect %q: got %q", options.signerCN, cn)
+		}
+	}
+}
+
+// AssertSignatureNotPresent fails the test if the subject has any signature.
+func AssertSignatureNotPresent(t testing.TB, repo notary.SignatureRepository, subject oci.Descriptor) {
+	t.Helper()
+	set, err := repo.LookupSet(context.Background(), subject.Digest)
+	if err != nil {
+		t.Fatalf("failed to lookup signatures of %v: %v", subject.Digest, err)
+	}
+	if n := set.Len(); n > 0 {
+		t.Fatalf("expect no signature for %v: got %d", subject.Digest, n)
+	}
+}
@@ -0,0 +1,87 @@
+package testutil
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"testing"
+
+	"github.com/docker/go/canonical/json"
+	"github.com/notaryproject/notary/v2/signature"
+	x509nv2 "github.com/notaryproject/notary/v2/signature/x509"
+)
+
+// mockAlgorithm is the algorithm declared in the headers of mock signatures
+const mockAlgorithm = "mock"
+
+type mockSigner struct {
+	sig  []byte
+	cert *x509.Certificate
+}
+
+// NewMockSigner returns a signer which always returns sig as the signature,
+// regardless of the payload, so that tests produce deterministic signatures.
+// If cert is not nil, it is set as the signing certificate in the x5c header.
+// Register it together with NewMockVerifier in a signature.Scheme to test
+// signing pipelines without real keys.
+func NewMockSigner(t testing.TB, sig []byte, cert *x509.Certificate) signature.Signer {
+	t.Helper()
+	if len(sig) == 0 {
+		t.Fatal("mock signer requires a signature")
+	}
+	return &mockSigner{
+		sig:  sig,
+		cert: cert,
+	}
+}
+
+func (s *mockSigner) Sign(claims string) (string, []byte, error) {
+	header := x509nv2.Header{
+		Header: signature.Header{
+			Type: x509nv2.Type,
+		},
+		Parameters: x509nv2.Parameters{
+			Algorithm: mockAlgorithm,
+		},
+	}
+	if s.cert != nil {
+		header.X5c = [][]byte{s.cert.Raw}
+	}
+	headerJSON, err := json.MarshalCanonical(header)
+	if err != nil {
+		return "", nil, err
+	}
+	sig := make([]byte, len(s.sig))
+	copy(sig, s.sig)
+	return signature.EncodeSegment(headerJSON) + "." + claims, sig, nil
+}
+
+type mockVerifier struct {
+	expectedSig []byte
+}
+
+// NewMockVerifier returns a verifier which accepts the signatures equal to
+// expectedSig only, regardless of the signed content.
+func NewMockVerifier(t testing.TB, expectedSig []byte) signature.Verifier {
+	t.Helper()
+	if len(expectedSig) == 0 {
+		t.Fatal("mock verifier requires an expected signature")
+	}
+	return &mockVerifier{
+		expectedSig: expectedSig,
+	}
+}
+
+func (v *mockVerifier) Type() string {
+	return x509nv2.Type
+}
+
+func (v *mockVerifier) Verify(header signature.Header, signed string, sig []byte) error {
+	if header.Type != x509nv2.Type {
+		return signature.ErrInvalidSignatureType
+	}
+	if !bytes.Equal(sig, v.expectedSig) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
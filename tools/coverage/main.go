@@ -0,0 +1,200 @@
+// Command coverage enforces a minimum test coverage.
+//
+// The threshold is applied per package rather than to the repository as a
+// whole, so that a new package with poor coverage cannot hide behind the
+// coverage of the established ones, and vice versa.
+//
+// The packages listed in the baseline file are held to their measured
+// coverage instead, so that they must not regress while the threshold applies
+// to new packages. Run with -update to record the current coverage as the
+// baseline.
+//
+// Usage:
+//
+//	go run ./tools/coverage [-threshold 80] [-profile coverage.out] [-baseline tools/coverage/baseline.txt] [-update]
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	threshold := flag.Float64("threshold", 80, "minimum line coverage percentage per package")
+	profile := flag.String("profile", "coverage.out", "path of the coverage profile to write")
+	baselinePath := flag.String("baseline", "tools/coverage/baseline.txt", "path of the per-package coverage baseline")
+	update := flag.Bool("update", false, "write the current coverage to the baseline instead of checking it")
+	flag.Parse()
+
+	if err := run("go", "test", "-coverprofile="+*profile, "./..."); err != nil {
+		fatal(err)
+	}
+	if err := run("go", "tool", "cover", "-func="+*profile); err != nil {
+		fatal(err)
+	}
+
+	coverage, err := readProfile(*profile)
+	if err != nil {
+		fatal(err)
+	}
+	packages := make([]string, 0, len(coverage))
+	for pkg := range coverage {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+
+	if *update {
+		if err := writeBaseline(*baselinePath, packages, coverage); err != nil {
+			fatal(err)
+		}
+		return
+	}
+	baseline, err := readBaseline(*baselinePath)
+	if err != nil {
+		fatal(err)
+	}
+
+	failed := false
+	for _, pkg := range packages {
+		percent := coverage[pkg].percent()
+		minimum, ok := baseline[pkg]
+		if !ok {
+			minimum = *threshold
+		}
+		status := "ok"
+		if percent < minimum {
+			status = "FAIL"
+			failed = true
+		}
+		fmt.Printf("%-4s %s %.1f%% (minimum %.1f%%)\n", status, pkg, percent, minimum)
+	}
+	if failed {
+		fatal(errors.New("coverage below the minimum"))
+	}
+}
+
+// statements counts the covered statements of a package.
+type statements struct {
+	total   int
+	covered int
+}
+
+func (s statements) percent() float64 {
+	if s.total == 0 {
+		return 100
+	}
+	return float64(s.covered) / float64(s.total) * 100
+}
+
+// readProfile reads a coverage profile and aggregates the statements by
+// package.
+func readProfile(name string) (map[string]statements, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	coverage := make(map[string]statements)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "mode:") {
+			continue
+		}
+
+		// line format: name.go:line.column,line.column numberOfStatements count
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid coverage profile line: %q", line)
+		}
+		block := fields[0]
+		i := strings.LastIndex(block, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid coverage profile line: %q", line)
+		}
+		numStmts, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, err
+		}
+
+		pkg := path.Dir(block[:i])
+		stmts := coverage[pkg]
+		stmts.total += numStmts
+		if count > 0 {
+			stmts.covered += numStmts
+		}
+		coverage[pkg] = stmts
+	}
+	return coverage, scanner.Err()
+}
+
+// readBaseline reads the minimum coverage of the packages in the baseline
+// file. Each line holds a package and its percentage, separated by a space.
+// Empty lines and lines starting with # are ignored. A missing file is an
+// empty baseline.
+func readBaseline(name string) (map[string]float64, error) {
+	baseline := make(map[string]float64)
+	file, err := os.Open(name)
+	if os.IsNotExist(err) {
+		return baseline, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid baseline line: %q", line)
+		}
+		percent, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid baseline line: %q", line)
+		}
+		baseline[fields[0]] = percent
+	}
+	return baseline, scanner.Err()
+}
+
+// writeBaseline records the coverage of the packages, rounded down to a whole
+// percentage so that timing dependent code paths do not make the check flaky.
+func writeBaseline(name string, packages []string, coverage map[string]statements) error {
+	var b strings.Builder
+	b.WriteString("# Minimum line coverage per package, written by go run ./tools/coverage -update\n")
+	for _, pkg := range packages {
+		fmt.Fprintf(&b, "%s %.1f\n", pkg, math.Floor(coverage[pkg].percent()))
+	}
+	return os.WriteFile(name, []byte(b.String()), 0644)
+}
+
+func run(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "coverage:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,56 @@
+package truststore
+
+import (
+	"crypto/x509"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	x509nv2 "github.com/notaryproject/notary/v2/signature/x509"
+)
+
+// certificateExtensions are the extensions of the certificate files in a
+// Notation trust store
+var certificateExtensions = map[string]bool{
+	".pem": true,
+	".crt": true,
+	".cer": true,
+}
+
+// LoadNotationTrustStore loads the certificates of a trust store set up by
+// the Notation CLI, laid out as {type}/{name}/ under dir, e.g.
+// ~/.config/notation/truststore. All the PEM encoded certificates found
+// under dir are added to the returned pool, regardless of the trust store
+// type and name.
+func LoadNotationTrustStore(dir string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	var count int
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() || !certificateExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		certs, err := x509nv2.ReadCertificateFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if len(certs) == 0 {
+			return fmt.Errorf("%s: no PEM encoded certificate found", path)
+		}
+		for _, cert := range certs {
+			pool.AddCert(cert)
+		}
+		count += len(certs)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("no certificate found in trust store %s", dir)
+	}
+	return pool, nil
+}
@@ -0,0 +1,122 @@
+package truststore_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notary/v2/truststore"
+)
+
+// newTestCertificate generates a self-signed certificate
+func newTestCertificate(t *testing.T, commonName string) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// writeFile writes the content to the path under dir, creating the parent
+// directories
+func writeFile(t *testing.T, dir, path string, content []byte) {
+	t.Helper()
+	path = filepath.Join(dir, path)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func encodeCertificate(cert *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+}
+
+func TestLoadNotationTrustStore(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCertificate(t, "ca")
+	signingAuthority := newTestCertificate(t, "signing authority")
+	untrusted := newTestCertificate(t, "untrusted")
+	writeFile(t, dir, filepath.Join("x509", "ca", "acme", "root.pem"), encodeCertificate(ca))
+	writeFile(t, dir, filepath.Join("x509", "signingAuthority", "acme", "sa.CRT"), encodeCertificate(signingAuthority))
+	// files of other extensions are not certificates
+	writeFile(t, dir, filepath.Join("x509", "ca", "acme", "README.md"), []byte("trust store"))
+	writeFile(t, dir, filepath.Join("x509", "ca", "acme", "untrusted.der"), untrusted.Raw)
+
+	pool, err := truststore.LoadNotationTrustStore(dir)
+	if err != nil {
+		t.Fatalf("LoadNotationTrustStore() error = %v", err)
+	}
+	for _, cert := range []*x509.Certificate{ca, signingAuthority} {
+		if _, err := cert.Verify(x509.VerifyOptions{Roots: pool}); err != nil {
+			t.Errorf("Verify(%s) error = %v", cert.Subject.CommonName, err)
+		}
+	}
+	if _, err := untrusted.Verify(x509.VerifyOptions{Roots: pool}); err == nil {
+		t.Error("Verify(untrusted) error = nil, want an unknown authority")
+	}
+}
+
+func TestLoadNotationTrustStoreErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		files map[string][]byte
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "no certificate",
+			files: map[string][]byte{
+				filepath.Join("x509", "ca", "acme", "README.md"): []byte("trust store"),
+			},
+		},
+		{
+			name: "not PEM",
+			files: map[string][]byte{
+				filepath.Join("x509", "ca", "acme", "root.pem"): []byte("not a certificate"),
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for path, content := range tt.files {
+				writeFile(t, dir, path, content)
+			}
+			if _, err := truststore.LoadNotationTrustStore(dir); err == nil {
+				t.Error("LoadNotationTrustStore() error = nil, want an error")
+			}
+		})
+	}
+
+	if _, err := truststore.LoadNotationTrustStore(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("LoadNotationTrustStore() of a missing directory error = nil, want an error")
+	}
+}
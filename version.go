@@ -0,0 +1,9 @@
+package notary
+
+// Version is the version of the notary library
+const Version = "2.0.0-beta.1"
+
+// GetVersion returns the version of the notary library
+func GetVersion() string {
+	return Version
+}
@@ -0,0 +1,39 @@
+package notary_test
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/notaryproject/notary/v2"
+	"github.com/notaryproject/notary/v2/registry"
+	"github.com/notaryproject/notary/v2/registry/registrytest"
+)
+
+// semverRegexp matches semantic versions with an optional pre-release
+var semverRegexp = regexp.MustCompile(`^\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?$`)
+
+func TestGetVersion(t *testing.T) {
+	version := notary.GetVersion()
+	if !semverRegexp.MatchString(version) {
+		t.Errorf("GetVersion() = %q, want a semantic version", version)
+	}
+	if version != notary.Version {
+		t.Errorf("GetVersion() = %q, want %q", version, notary.Version)
+	}
+}
+
+func TestUserAgentVersion(t *testing.T) {
+	server := registrytest.NewServer()
+	defer server.Close()
+	repo := registry.NewRepository(http.DefaultTransport, server.Host(), "test/app", true)
+	if err := repo.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	want := "notary/" + notary.GetVersion() + " "
+	if got := server.LastHeader().Get("User-Agent"); !strings.HasPrefix(got, want) {
+		t.Errorf("User-Agent = %q, want the prefix %q", got, want)
+	}
+}